@@ -0,0 +1,37 @@
+// Package digenrt contains runtime helpers for containers generated by DIGEN.
+// Ready-made construction hooks are provided by the digenrt/hooks subpackage.
+package digenrt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrUnexpectedType is returned by Get when the service does not implement the requested type.
+var ErrUnexpectedType = errors.New("unexpected service type")
+
+// Container resolves services by name. It is implemented by generated public containers
+// having services with the "named" option.
+type Container interface {
+	Get(ctx context.Context, name string) (any, error)
+}
+
+// Get resolves the service by name and asserts it to the type T.
+//
+//	repository, err := digenrt.Get[domain.EntityRepository](ctx, c, "Repositories.EntityRepository")
+func Get[T any](ctx context.Context, c Container, name string) (T, error) {
+	var zero T
+
+	s, err := c.Get(ctx, name)
+	if err != nil {
+		return zero, err
+	}
+	service, ok := s.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: service %s of type %T is not %s", ErrUnexpectedType, name, s, reflect.TypeFor[T]())
+	}
+
+	return service, nil
+}
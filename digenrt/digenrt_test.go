@@ -0,0 +1,48 @@
+package digenrt_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/digen/digenrt"
+)
+
+var errNotFound = errors.New("service not found")
+
+type container map[string]any
+
+func (c container) Get(ctx context.Context, name string) (any, error) {
+	s, ok := c[name]
+	if !ok {
+		return nil, fmt.Errorf("get %s: %w", name, errNotFound)
+	}
+
+	return s, nil
+}
+
+func TestGet(t *testing.T) {
+	c := container{"Reader": strings.NewReader("data")}
+
+	t.Run("service of type", func(t *testing.T) {
+		reader, err := digenrt.Get[io.Reader](context.Background(), c, "Reader")
+
+		assert.NoError(t, err)
+		assert.NotNil(t, reader)
+	})
+	t.Run("service of unexpected type", func(t *testing.T) {
+		_, err := digenrt.Get[io.Writer](context.Background(), c, "Reader")
+
+		assert.ErrorIs(t, err, digenrt.ErrUnexpectedType)
+		assert.EqualError(t, err, "unexpected service type: service Reader of type *strings.Reader is not io.Writer")
+	})
+	t.Run("service not found", func(t *testing.T) {
+		_, err := digenrt.Get[io.Reader](context.Background(), c, "Writer")
+
+		assert.ErrorIs(t, err, errNotFound)
+	})
+}
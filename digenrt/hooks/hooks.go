@@ -0,0 +1,41 @@
+// Package hooks provides ready-made hooks observing construction of services
+// by containers generated with the "hooks" option.
+//
+// Hooks are set up by the injector of the generated public container:
+//
+//	c, err := di.NewContainer(di.SetHooks(hooks.Slog(slog.Default())))
+package hooks
+
+import (
+	"context"
+	"time"
+)
+
+// Hooks observes construction of services. It is compatible with the Hooks interface
+// of generated containers.
+type Hooks interface {
+	OnBuildStart(ctx context.Context, id string) context.Context
+	OnBuildDone(ctx context.Context, id string, duration time.Duration, err error)
+}
+
+// Chain combines hooks into a single one. Hooks are started in the given order
+// and finished in the reverse order.
+func Chain(hooks ...Hooks) Hooks {
+	return chain(hooks)
+}
+
+type chain []Hooks
+
+func (c chain) OnBuildStart(ctx context.Context, id string) context.Context {
+	for _, h := range c {
+		ctx = h.OnBuildStart(ctx, id)
+	}
+
+	return ctx
+}
+
+func (c chain) OnBuildDone(ctx context.Context, id string, duration time.Duration, err error) {
+	for i := len(c) - 1; i >= 0; i-- {
+		c[i].OnBuildDone(ctx, id, duration, err)
+	}
+}
@@ -0,0 +1,90 @@
+package hooks_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/digen/digenrt/hooks"
+)
+
+type fakeTracer struct {
+	events []string
+}
+
+type fakeSpan struct {
+	name   string
+	tracer *fakeTracer
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, hooks.Span) {
+	t.events = append(t.events, "start "+name)
+
+	return ctx, &fakeSpan{name: name, tracer: t}
+}
+
+func (s *fakeSpan) RecordError(err error) {
+	s.tracer.events = append(s.tracer.events, "error "+s.name+": "+err.Error())
+}
+
+func (s *fakeSpan) End() {
+	s.tracer.events = append(s.tracer.events, "end "+s.name)
+}
+
+func TestTracing(t *testing.T) {
+	tracer := &fakeTracer{}
+	h := hooks.Tracing(tracer)
+
+	handlerCtx := h.OnBuildStart(context.Background(), "Handler")
+	connectionCtx := h.OnBuildStart(handlerCtx, "Connection")
+	h.OnBuildDone(connectionCtx, "Connection", time.Millisecond, errors.New("refused"))
+	h.OnBuildDone(handlerCtx, "Handler", time.Millisecond, nil)
+
+	assert.Equal(t, []string{
+		"start di.build Handler",
+		"start di.build Connection",
+		"error di.build Connection: refused",
+		"end di.build Connection",
+		"end di.build Handler",
+	}, tracer.events)
+}
+
+func TestSlog(t *testing.T) {
+	var output bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&output, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	h := hooks.Slog(logger)
+
+	ctx := h.OnBuildStart(context.Background(), "Connection")
+	h.OnBuildDone(ctx, "Connection", time.Second, errors.New("refused"))
+	h.OnBuildDone(ctx, "Handler", time.Second, nil)
+
+	assert.Equal(t,
+		"level=ERROR msg=\"service build failed\" service=Connection duration=1s error=refused\n"+
+			"level=DEBUG msg=\"service built\" service=Handler duration=1s\n",
+		output.String(),
+	)
+}
+
+func TestChain(t *testing.T) {
+	first := &fakeTracer{}
+	second := &fakeTracer{}
+	h := hooks.Chain(hooks.Tracing(first), hooks.Tracing(second))
+
+	ctx := h.OnBuildStart(context.Background(), "Handler")
+	h.OnBuildDone(ctx, "Handler", time.Millisecond, nil)
+
+	assert.Equal(t, []string{"start di.build Handler", "end di.build Handler"}, first.events)
+	assert.Equal(t, []string{"start di.build Handler", "end di.build Handler"}, second.events)
+}
@@ -0,0 +1,38 @@
+package hooks
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SlogHooks logs construction of services via log/slog. Successfully built services
+// are logged with debug level, failures are logged with error level.
+type SlogHooks struct {
+	logger *slog.Logger
+}
+
+// Slog creates hooks logging construction of services by the logger.
+func Slog(logger *slog.Logger) *SlogHooks {
+	return &SlogHooks{logger: logger}
+}
+
+func (h *SlogHooks) OnBuildStart(ctx context.Context, id string) context.Context {
+	return ctx
+}
+
+func (h *SlogHooks) OnBuildDone(ctx context.Context, id string, duration time.Duration, err error) {
+	if err != nil {
+		h.logger.LogAttrs(ctx, slog.LevelError, "service build failed",
+			slog.String("service", id),
+			slog.Duration("duration", duration),
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	h.logger.LogAttrs(ctx, slog.LevelDebug, "service built",
+		slog.String("service", id),
+		slog.Duration("duration", duration),
+	)
+}
@@ -0,0 +1,68 @@
+package hooks
+
+import (
+	"context"
+	"time"
+)
+
+// Tracer starts spans. It is shaped after the OpenTelemetry tracer, so the adapter
+// is a thin wrapper around trace.Tracer and trace.Span:
+//
+//	type otelTracer struct{ tracer trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, hooks.Span) {
+//		ctx, span := t.tracer.Start(ctx, name)
+//		return ctx, otelSpan{span}
+//	}
+//
+//	type otelSpan struct{ span trace.Span }
+//
+//	func (s otelSpan) RecordError(err error) {
+//		s.span.RecordError(err)
+//		s.span.SetStatus(codes.Error, err.Error())
+//	}
+//
+//	func (s otelSpan) End() { s.span.End() }
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is the span of the service construction.
+type Span interface {
+	RecordError(err error)
+	End()
+}
+
+// TracingHooks starts a span for every service construction. Spans of dependencies
+// are nested into the span of the dependent service.
+type TracingHooks struct {
+	tracer Tracer
+}
+
+// Tracing creates hooks tracing construction of services by the tracer.
+// Spans are named as "di.build <service id>".
+func Tracing(tracer Tracer) *TracingHooks {
+	return &TracingHooks{tracer: tracer}
+}
+
+// spanKey is bound to the hooks instance, so chained tracers do not override spans of each other.
+type spanKey struct {
+	hooks *TracingHooks
+}
+
+func (h *TracingHooks) OnBuildStart(ctx context.Context, id string) context.Context {
+	ctx, span := h.tracer.Start(ctx, "di.build "+id)
+
+	return context.WithValue(ctx, spanKey{hooks: h}, span)
+}
+
+func (h *TracingHooks) OnBuildDone(ctx context.Context, id string, duration time.Duration, err error) {
+	span, ok := ctx.Value(spanKey{hooks: h}).(Span)
+	if !ok {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
@@ -10,6 +10,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	container, err := di.NewContainer(config.Params{
 		DatabaseURL: os.Getenv("DATABASE_URL"),
 	})
@@ -18,12 +19,14 @@ func main() {
 	}
 
 	// get published service from di container
-	server, err := container.Server(context.Background())
+	server, err := container.Server(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
 	if err := server.ListenAndServe(); err != nil {
+		log.Println(err)
+	}
+	if err := container.Close(ctx); err != nil {
 		log.Fatal(err)
 	}
-	container.Close()
 }
@@ -1,5 +1,5 @@
 // Code generated by DIGEN; DO NOT EDIT.
-// This file was generated by Dependency Injection Container Generator rev-4a52da2-dirty.
+// This file was generated by Dependency Injection Container Generator rev-bcc40d7.
 // See docs at https://github.com/strider2038/digen
 package di
 
@@ -22,6 +22,10 @@ type Container struct {
 
 type Injector func(c *Container) error
 
+// ServiceError is the initialization error of the service, it contains the chain of dependencies
+// from the requested service to the failed one.
+type ServiceError = internal.ServiceError
+
 func NewContainer(config config.Params, injectors ...Injector) (*Container, error) {
 	c := &Container{
 		c:  internal.NewContainer(),
@@ -52,8 +56,11 @@ func (c *Container) Server(ctx context.Context) (s *http.Server, err error) {
 
 	s = c.c.Server(ctx)
 	err = c.c.Error()
+	if err != nil {
+		return s, fmt.Errorf("get Server: %w", err)
+	}
 
-	return s, err
+	return s, nil
 }
 
 func (c *Container) FindEntityHandler(ctx context.Context) (s *httphandler.FindEntity, err error) {
@@ -68,8 +75,11 @@ func (c *Container) FindEntityHandler(ctx context.Context) (s *httphandler.FindE
 
 	s = c.c.API().(*internal.APIContainer).FindEntityHandler(ctx)
 	err = c.c.Error()
+	if err != nil {
+		return s, fmt.Errorf("get FindEntityHandler: %w", err)
+	}
 
-	return s, err
+	return s, nil
 }
 
 func SetEntityRepository(s domain.EntityRepository) Injector {
@@ -80,11 +90,11 @@ func SetEntityRepository(s domain.EntityRepository) Injector {
 	}
 }
 
-func (c *Container) Close() {
+func (c *Container) Close(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.c.Close()
+	return c.c.Close(ctx)
 }
 
 func newRecoveredError(recovered any, err error) error {
@@ -1,5 +1,5 @@
 // Code generated by DIGEN; DO NOT EDIT.
-// This file was generated by Dependency Injection Container Generator rev-4a52da2-dirty.
+// This file was generated by Dependency Injection Container Generator rev-bcc40d7.
 // See docs at https://github.com/strider2038/digen
 package internal
 
@@ -13,8 +13,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -32,8 +34,9 @@ const (
 )
 
 type Container struct {
-	errs []error
-	init bitset
+	errs    []error
+	init    bitset
+	closers []int
 
 	config config.Params
 	logger *log.Logger
@@ -106,6 +109,7 @@ func (c *Container) Config(ctx context.Context) config.Params {
 
 func (c *Container) Logger(ctx context.Context) *log.Logger {
 	if !c.init.IsSet(id_Logger) && c.errs == nil {
+		ctx = withService(ctx, "Logger")
 		c.logger = factories.CreateLogger(ctx, c)
 		c.init.Set(id_Logger)
 	}
@@ -114,7 +118,9 @@ func (c *Container) Logger(ctx context.Context) *log.Logger {
 
 func (c *Container) DB(ctx context.Context) *sql.DB {
 	if !c.init.IsSet(id_DB) && c.errs == nil {
+		ctx = withService(ctx, "DB")
 		c.db = factories.CreateDB(ctx, c)
+		c.closers = append(c.closers, id_DB)
 		c.init.Set(id_DB)
 	}
 	return c.db
@@ -122,7 +128,9 @@ func (c *Container) DB(ctx context.Context) *sql.DB {
 
 func (c *Container) Server(ctx context.Context) *http.Server {
 	if !c.init.IsSet(id_Server) && c.errs == nil {
+		ctx = withService(ctx, "Server")
 		c.server = factories.CreateServer(ctx, c)
+		c.closers = append(c.closers, id_Server)
 		c.init.Set(id_Server)
 	}
 	return c.server
@@ -134,6 +142,7 @@ func (c *Container) Params() lookup.ParamsContainer {
 
 func (c *ParamsContainer) ServerPort(ctx context.Context) int {
 	if !c.init.IsSet(id_Params_ServerPort) && c.errs == nil {
+		ctx = withService(ctx, "Params.ServerPort")
 		c.serverPort = factories.CreateParamsServerPort(ctx, c)
 		c.init.Set(id_Params_ServerPort)
 	}
@@ -142,6 +151,7 @@ func (c *ParamsContainer) ServerPort(ctx context.Context) int {
 
 func (c *ParamsContainer) ServerHost(ctx context.Context) string {
 	if !c.init.IsSet(id_Params_ServerHost) && c.errs == nil {
+		ctx = withService(ctx, "Params.ServerHost")
 		c.serverHost = factories.CreateParamsServerHost(ctx, c)
 		c.init.Set(id_Params_ServerHost)
 	}
@@ -150,6 +160,7 @@ func (c *ParamsContainer) ServerHost(ctx context.Context) string {
 
 func (c *ParamsContainer) RequestTimeout(ctx context.Context) time.Duration {
 	if !c.init.IsSet(id_Params_RequestTimeout) && c.errs == nil {
+		ctx = withService(ctx, "Params.RequestTimeout")
 		c.requestTimeout = factories.CreateParamsRequestTimeout(ctx, c)
 		c.init.Set(id_Params_RequestTimeout)
 	}
@@ -162,6 +173,7 @@ func (c *Container) API() lookup.APIContainer {
 
 func (c *APIContainer) FindEntityHandler(ctx context.Context) *httphandler.FindEntity {
 	if !c.init.IsSet(id_API_FindEntityHandler) && c.errs == nil {
+		ctx = withService(ctx, "API.FindEntityHandler")
 		c.findEntityHandler = factories.CreateAPIFindEntityHandler(ctx, c)
 		c.init.Set(id_API_FindEntityHandler)
 	}
@@ -174,6 +186,7 @@ func (c *Container) UseCases() lookup.UseCaseContainer {
 
 func (c *UseCaseContainer) FindEntity(ctx context.Context) *usecase.FindEntity {
 	if !c.init.IsSet(id_UseCases_FindEntity) && c.errs == nil {
+		ctx = withService(ctx, "UseCases.FindEntity")
 		c.findEntity = factories.CreateUseCasesFindEntity(ctx, c)
 		c.init.Set(id_UseCases_FindEntity)
 	}
@@ -186,6 +199,7 @@ func (c *Container) Repositories() lookup.RepositoryContainer {
 
 func (c *RepositoryContainer) EntityRepository(ctx context.Context) domain.EntityRepository {
 	if !c.init.IsSet(id_Repositories_EntityRepository) && c.errs == nil {
+		ctx = withService(ctx, "Repositories.EntityRepository")
 		c.entityRepository = factories.CreateRepositoriesEntityRepository(ctx, c)
 		c.init.Set(id_Repositories_EntityRepository)
 	}
@@ -202,11 +216,105 @@ func (c *RepositoryContainer) SetEntityRepository(s domain.EntityRepository) {
 	c.init.Set(id_Repositories_EntityRepository)
 }
 
-func (c *Container) Close() {
-	if c.init.IsSet(id_DB) {
-		c.db.Close()
+// Close closes initialized services in reverse order of their initialization.
+// Every closer is limited by the context deadline, closers are never run concurrently:
+// after the deadline the remaining services are skipped with the context error.
+// All closing errors are joined.
+func (c *Container) Close(ctx context.Context) error {
+	closers := c.closers
+	c.closers = nil
+
+	errs := make([]error, 0, len(closers))
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := c.closeService(ctx, closers[i]); err != nil {
+			errs = append(errs, err)
+		}
 	}
-	if c.init.IsSet(id_Server) {
-		c.server.Close()
+
+	return errors.Join(errs...)
+}
+
+func (c *Container) closeService(ctx context.Context, id int) error {
+	switch id {
+	case id_DB:
+		if err := closeWithContext(ctx, func(ctx context.Context) error {
+			return c.db.Close()
+		}); err != nil {
+			return fmt.Errorf("close DB: %w", err)
+		}
+	case id_Server:
+		if err := closeWithContext(ctx, func(ctx context.Context) error {
+			return c.server.Close()
+		}); err != nil {
+			return fmt.Errorf("close Server: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func closeWithContext(ctx context.Context, closer func(context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- closer(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ServiceError is the initialization error of the service. Path contains the chain of services
+// from the requested one to the failed one.
+type ServiceError struct {
+	ID   string
+	Path []string
+	Err  error
+}
+
+func (e *ServiceError) Error() string {
+	return "create " + strings.Join(e.Path, " -> ") + ": " + e.Err.Error()
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+// newServiceError wraps the factory error of the service resolved by the context.
+// Errors of dependencies already contain the full path, so they are returned as is.
+func newServiceError(ctx context.Context, err error) error {
+	var serviceErr *ServiceError
+	if errors.As(err, &serviceErr) {
+		return err
+	}
+
+	path := servicePath(ctx)
+
+	return &ServiceError{
+		Err:  err,
+		ID:   path[len(path)-1],
+		Path: path,
+	}
+}
+
+type servicePathKey struct{}
+
+func servicePath(ctx context.Context) []string {
+	path, _ := ctx.Value(servicePathKey{}).([]string)
+
+	return path
+}
+
+// withService adds the service into the resolution path carried by the context.
+func withService(ctx context.Context, name string) context.Context {
+	path := servicePath(ctx)
+
+	return context.WithValue(ctx, servicePathKey{}, append(path[:len(path):len(path)], name))
 }
@@ -1,5 +1,5 @@
 // Code generated by DIGEN; DO NOT EDIT.
-// This file was generated by Dependency Injection Container Generator rev-4a52da2-dirty.
+// This file was generated by Dependency Injection Container Generator rev-bcc40d7.
 // See docs at https://github.com/strider2038/digen
 package lookup
 
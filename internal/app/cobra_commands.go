@@ -24,6 +24,7 @@ func newMainCommand(opts *Options) *cobra.Command {
 		newVersionCommand(opts),
 		newInitCommand(opts),
 		newGenerateCommand(opts),
+		newExportCommand(opts),
 	)
 
 	return command
@@ -52,13 +53,47 @@ func newInitCommand(options *Options) *cobra.Command {
 }
 
 func newGenerateCommand(options *Options) *cobra.Command {
-	return &cobra.Command{
+	var syncFactories bool
+
+	command := &cobra.Command{
 		Use:           "generate",
 		Short:         "Generates Dependency Injection Containers",
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runGenerate(options)
+			return runGenerate(options, syncFactories)
+		},
+	}
+
+	command.Flags().BoolVar(
+		&syncFactories,
+		"sync-factories",
+		false,
+		`Rewrites signatures of existing factories mismatching service definitions.`,
+	)
+
+	return command
+}
+
+func newExportCommand(options *Options) *cobra.Command {
+	var schema bool
+
+	command := &cobra.Command{
+		Use:           "export",
+		Short:         "Prints parsed model of Dependency Injection Container as JSON",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExport(options, cmd.OutOrStdout(), schema)
 		},
 	}
+
+	command.Flags().BoolVar(
+		&schema,
+		"schema",
+		false,
+		`Prints JSON schema of the exported document.`,
+	)
+
+	return command
 }
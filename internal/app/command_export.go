@@ -0,0 +1,48 @@
+package app
+
+import (
+	"io"
+	"os"
+
+	"github.com/muonsoft/errors"
+	"github.com/pterm/pterm"
+	"github.com/strider2038/digen/internal/config"
+	"github.com/strider2038/digen/internal/di"
+)
+
+func runExport(options *Options, w io.Writer, schema bool) error {
+	if schema {
+		_, err := w.Write(di.ExportSchema)
+
+		return err
+	}
+
+	params, err := config.Load()
+	if err != nil {
+		return errors.Errorf("load config: %w", err)
+	}
+
+	generator := newGenerator(options, params)
+	generator.Logger = stderrLogger{}
+
+	return generator.Export(w)
+}
+
+// stderrLogger writes messages into stderr to keep the exported document clean in stdout.
+type stderrLogger struct{}
+
+func (log stderrLogger) Debug(a ...any) {
+	pterm.Debug.WithWriter(os.Stderr).Println(a...)
+}
+
+func (log stderrLogger) Info(a ...interface{}) {
+	pterm.Info.WithWriter(os.Stderr).Println(a...)
+}
+
+func (log stderrLogger) Success(a ...interface{}) {
+	pterm.Success.WithWriter(os.Stderr).Println(a...)
+}
+
+func (log stderrLogger) Warning(a ...interface{}) {
+	pterm.Warning.WithWriter(os.Stderr).Println(a...)
+}
@@ -1,29 +1,44 @@
 package app
 
 import (
+	"time"
+
 	"github.com/muonsoft/errors"
 	"github.com/pterm/pterm"
 	"github.com/strider2038/digen/internal/config"
 	"github.com/strider2038/digen/internal/di"
 )
 
-func runGenerate(options *Options) error {
+func runGenerate(options *Options, syncFactories bool) error {
 	params, err := config.Load()
 	if err != nil {
 		return errors.Errorf("load config: %w", err)
 	}
 
-	return newGenerator(options, params).Generate()
+	generator := newGenerator(options, params)
+	generator.SyncFactories = syncFactories
+
+	return generator.Generate()
 }
 
 func newGenerator(options *Options, params *config.Parameters) *di.Generator {
 	return &di.Generator{
-		BaseDir: params.Container.Dir,
-		Logger:  terminalLogger{},
+		BaseDir:         params.Container.Dir,
+		DefinitionsFile: params.Container.Definitions,
+		Logger:          terminalLogger{},
 		Params: di.GenerationParameters{
 			Version:       options.Version,
 			Factories:     params.Factories.MapToOptions(),
 			ErrorHandling: params.ErrorHandling.MapToOptions(),
+			TypeCheck:     params.Container.TypeCheck,
+			Concurrency:   di.ConcurrencyMode(params.Container.Concurrency),
+			Hooks:         params.Container.Hooks,
+			Describe:      params.Container.Describe,
+			TestSupport:   params.Container.TestSupport,
+			LookupFakes:   params.Container.LookupFakes,
+			StopTimeout:   time.Duration(params.Container.StopTimeout),
+			Templates:     params.Templates.MapToOptions(),
+			Layout:        params.Container.Layout.MapToOptions(),
 		},
 	}
 }
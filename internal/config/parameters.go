@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/muonsoft/errors"
 	"github.com/strider2038/digen/internal/di"
 )
@@ -12,48 +14,145 @@ type Parameters struct {
 	Container     Container     `json:"container" yaml:"container"`
 	Factories     Factories     `json:"factories,omitempty" yaml:"factories,omitempty"`
 	ErrorHandling ErrorHandling `json:"errorHandling,omitempty" yaml:"errorHandling,omitempty"`
+	Templates     Templates     `json:"templates,omitempty" yaml:"templates,omitempty"`
 }
 
 type Container struct {
-	Dir string `json:"dir" yaml:"dir"`
+	Dir         string   `json:"dir" yaml:"dir"`
+	Definitions string   `json:"definitions,omitempty" yaml:"definitions,omitempty"`
+	TypeCheck   bool     `json:"typeCheck,omitempty" yaml:"typeCheck,omitempty"`
+	Concurrency string   `json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+	Hooks       bool     `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	Describe    bool     `json:"describe,omitempty" yaml:"describe,omitempty"`
+	TestSupport bool     `json:"testSupport,omitempty" yaml:"testSupport,omitempty"`
+	LookupFakes bool     `json:"lookupFakes,omitempty" yaml:"lookupFakes,omitempty"`
+	StopTimeout Duration `json:"stopTimeout,omitempty" yaml:"stopTimeout,omitempty"`
+	Layout      Layout   `json:"layout,omitempty" yaml:"layout,omitempty"`
+}
+
+// Layout defines directories relative to the container dir and names of the generated packages.
+type Layout struct {
+	Public      Package `json:"public,omitempty" yaml:"public,omitempty"`
+	Internal    Package `json:"internal,omitempty" yaml:"internal,omitempty"`
+	Definitions Package `json:"definitions,omitempty" yaml:"definitions,omitempty"`
+	Factories   Package `json:"factories,omitempty" yaml:"factories,omitempty"`
+	Lookup      Package `json:"lookup,omitempty" yaml:"lookup,omitempty"`
+	Testing     Package `json:"testing,omitempty" yaml:"testing,omitempty"`
+	LookupTest  Package `json:"lookupTest,omitempty" yaml:"lookupTest,omitempty"`
+}
+
+func (l Layout) MapToOptions() di.PackageLayout {
+	return di.PackageLayout{
+		Public:      l.Public.mapToOptions(),
+		Internal:    l.Internal.mapToOptions(),
+		Definitions: l.Definitions.mapToOptions(),
+		Factories:   l.Factories.mapToOptions(),
+		Lookup:      l.Lookup.mapToOptions(),
+		Testing:     l.Testing.mapToOptions(),
+		LookupTest:  l.LookupTest.mapToOptions(),
+	}
+}
+
+type Package struct {
+	Dir  string `json:"dir,omitempty" yaml:"dir,omitempty"`
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+func (p Package) mapToOptions() di.PackageOptions {
+	return di.PackageOptions{Dir: p.Dir, Name: p.Name}
 }
 
 type Factories struct {
-	ReturnError *bool `json:"returnError,omitempty" yaml:"returnError,omitempty"`
+	ReturnError   *bool  `json:"returnError,omitempty" yaml:"returnError,omitempty"`
+	Prefix        string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Context       *bool  `json:"context,omitempty" yaml:"context,omitempty"`
+	ContainerType string `json:"containerType,omitempty" yaml:"containerType,omitempty"`
+	Struct        string `json:"struct,omitempty" yaml:"struct,omitempty"`
 }
 
 func (f Factories) MapToOptions() di.FactoriesParameters {
-	parameters := di.FactoriesParameters{}
+	parameters := di.FactoriesParameters{
+		Prefix:        f.Prefix,
+		ContainerType: f.ContainerType,
+		Struct:        f.Struct,
+	}
 	if f.ReturnError != nil {
 		parameters.SkipError = !*f.ReturnError
 	}
+	if f.Context != nil {
+		parameters.SkipContext = !*f.Context
+	}
 
 	return parameters
 }
 
+// Templates customize the generated files, paths of templates are relative to the working dir.
+type Templates struct {
+	Header    string            `json:"header,omitempty" yaml:"header,omitempty"`
+	BuildTags string            `json:"buildTags,omitempty" yaml:"buildTags,omitempty"`
+	Overrides map[string]string `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+	Extras    map[string]string `json:"extras,omitempty" yaml:"extras,omitempty"`
+}
+
+func (t Templates) MapToOptions() di.TemplatesParameters {
+	return di.TemplatesParameters{
+		Header:    t.Header,
+		BuildTags: t.BuildTags,
+		Overrides: t.Overrides,
+		Extras:    t.Extras,
+	}
+}
+
 type ErrorHandling struct {
-	New  ErrorOptions     `json:"new,omitempty" yaml:"new,omitempty"`
-	Join ErrorOptions     `json:"join,omitempty" yaml:"join,omitempty"`
-	Wrap WrapErrorOptions `json:"wrap,omitempty" yaml:"wrap,omitempty"`
+	New           ErrorOptions     `json:"new,omitempty" yaml:"new,omitempty"`
+	Join          ErrorOptions     `json:"join,omitempty" yaml:"join,omitempty"`
+	Wrap          WrapErrorOptions `json:"wrap,omitempty" yaml:"wrap,omitempty"`
+	Policy        string           `json:"policy,omitempty" yaml:"policy,omitempty"`
+	RetryBackoff  Duration         `json:"retryBackoff,omitempty" yaml:"retryBackoff,omitempty"`
+	Explicit      bool             `json:"explicit,omitempty" yaml:"explicit,omitempty"`
+	RecoverPanics bool             `json:"recoverPanics,omitempty" yaml:"recoverPanics,omitempty"`
 }
 
 func (h ErrorHandling) MapToOptions() di.ErrorHandling {
 	return di.ErrorHandling{
-		New:  h.New.mapToOptions(),
-		Join: h.Join.mapToOptions(),
-		Wrap: h.Wrap.mapToOptions(),
+		New:           h.New.mapToOptions(),
+		Join:          h.Join.mapToOptions(),
+		Wrap:          h.Wrap.mapToOptions(),
+		Policy:        di.ErrorPolicy(h.Policy),
+		RetryBackoff:  time.Duration(h.RetryBackoff),
+		Explicit:      h.Explicit,
+		RecoverPanics: h.RecoverPanics,
+	}
+}
+
+// Duration is presented in config as a string like "1.5s" or "300ms".
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return errors.Errorf("parse duration: %w", err)
 	}
+	*d = Duration(duration)
+
+	return nil
 }
 
 type ErrorOptions struct {
 	Pkg  string `json:"pkg,omitempty" yaml:"pkg,omitempty"`
 	Func string `json:"func,omitempty" yaml:"func,omitempty"`
+	Args string `json:"args,omitempty" yaml:"args,omitempty"`
 }
 
 func (o ErrorOptions) mapToOptions() di.ErrorOptions {
 	return di.ErrorOptions{
-		Package:  o.Pkg,
-		Function: o.Func,
+		Package:   o.Pkg,
+		Function:  o.Func,
+		Arguments: di.ArgumentsLayout(o.Args),
 	}
 }
 
@@ -61,12 +160,14 @@ type WrapErrorOptions struct {
 	Pkg  string `json:"pkg,omitempty" yaml:"pkg,omitempty"`
 	Func string `json:"func,omitempty" yaml:"func,omitempty"`
 	Verb string `json:"verb,omitempty" yaml:"verb,omitempty"`
+	Args string `json:"args,omitempty" yaml:"args,omitempty"`
 }
 
 func (o WrapErrorOptions) mapToOptions() di.ErrorOptions {
 	return di.ErrorOptions{
-		Package:  o.Pkg,
-		Function: o.Func,
-		Verb:     o.Verb,
+		Package:   o.Pkg,
+		Function:  o.Func,
+		Verb:      o.Verb,
+		Arguments: di.ArgumentsLayout(o.Args),
 	}
 }
@@ -0,0 +1,241 @@
+package di_test
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/strider2038/digen/internal/di"
+)
+
+// TestGenerator_Generate_RaceDetector generates containers into the test module
+// from testdata/race and runs its tests with the race detector enabled. The module is assembled
+// from the shared testdata/race/module and fixtures of the test case copied over it.
+func TestGenerator_Generate_RaceDetector(t *testing.T) {
+	if testing.Short() {
+		t.Skip("race detector tests are skipped in short mode")
+	}
+	goBinary, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go binary not found")
+	}
+
+	// fixtures shared by test cases: the container of domain services and its factories for explicit errors
+	container := []string{"container"}
+	explicitContainer := []string{"container", "explicit_container"}
+
+	tests := []struct {
+		name string
+		// fixtures are directories of testdata/race copied over the shared module in order,
+		// the directory named by the test case is copied last if it exists
+		fixtures []string
+		params   di.GenerationParameters
+	}{
+		{
+			name:     "service_concurrency",
+			fixtures: container,
+			params:   di.GenerationParameters{Concurrency: di.ServiceConcurrency},
+		},
+		{
+			name:     "error_isolation",
+			fixtures: container,
+			params: di.GenerationParameters{
+				Concurrency: di.ServiceConcurrency,
+				ErrorHandling: di.ErrorHandling{
+					Policy:       di.IsolateErrorPolicy,
+					RetryBackoff: 50 * time.Millisecond,
+				},
+			},
+		},
+		{
+			name:     "explicit_errors",
+			fixtures: explicitContainer,
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{Explicit: true},
+			},
+		},
+		{
+			name:     "panic_recovery",
+			fixtures: container,
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{RecoverPanics: true},
+			},
+		},
+		{
+			name:     "construction_hooks",
+			fixtures: container,
+			params: di.GenerationParameters{
+				Concurrency: di.ServiceConcurrency,
+				Hooks:       true,
+			},
+		},
+		{
+			name:     "named_services",
+			fixtures: explicitContainer,
+			params: di.GenerationParameters{
+				Concurrency:   di.ServiceConcurrency,
+				ErrorHandling: di.ErrorHandling{Explicit: true},
+			},
+		},
+		{
+			name:     "test_support",
+			fixtures: container,
+			params: di.GenerationParameters{
+				Concurrency: di.ServiceConcurrency,
+				TestSupport: true,
+			},
+		},
+		{
+			name: "env_parameters",
+			params: di.GenerationParameters{
+				Concurrency:   di.ServiceConcurrency,
+				ErrorHandling: di.ErrorHandling{Policy: di.IsolateErrorPolicy},
+			},
+		},
+		{
+			name: "literal_values",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{Explicit: true, RecoverPanics: true},
+			},
+		},
+		{
+			name: "field_bindings",
+			params: di.GenerationParameters{
+				Concurrency:   di.ServiceConcurrency,
+				ErrorHandling: di.ErrorHandling{Explicit: true},
+			},
+		},
+		{
+			name:     "lookup_fakes",
+			fixtures: explicitContainer,
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{Explicit: true},
+				LookupFakes:   true,
+			},
+		},
+		{
+			name:     "service_description",
+			fixtures: container,
+			params: di.GenerationParameters{
+				Concurrency:   di.ServiceConcurrency,
+				ErrorHandling: di.ErrorHandling{Policy: di.IsolateErrorPolicy},
+				Describe:      true,
+			},
+		},
+		{
+			name: "package_layout",
+			params: di.GenerationParameters{
+				TestSupport: true,
+				LookupFakes: true,
+				Layout: di.PackageLayout{
+					Public:      di.PackageOptions{Name: "container"},
+					Internal:    di.PackageOptions{Name: "impl"},
+					Definitions: di.PackageOptions{Dir: "definitions"},
+					Factories:   di.PackageOptions{Dir: "providers"},
+					Lookup:      di.PackageOptions{Dir: "contracts"},
+					Testing:     di.PackageOptions{Dir: "testkit"},
+					LookupTest:  di.PackageOptions{Dir: "contracts/fakes"},
+				},
+			},
+		},
+		{
+			name:   "factory_signatures",
+			params: di.GenerationParameters{Factories: di.FactoriesParameters{Prefix: "New"}},
+		},
+		{
+			name:     "factory_signatures_with_explicit_errors",
+			fixtures: []string{"factory_signatures"},
+			params: di.GenerationParameters{
+				Factories:     di.FactoriesParameters{Prefix: "New"},
+				ErrorHandling: di.ErrorHandling{Explicit: true},
+			},
+		},
+		{
+			name:     "factory_signatures_with_recovered_panics",
+			fixtures: []string{"factory_signatures"},
+			params: di.GenerationParameters{
+				Factories:   di.FactoriesParameters{Prefix: "New"},
+				Concurrency: di.ServiceConcurrency,
+				ErrorHandling: di.ErrorHandling{
+					Policy:        di.IsolateErrorPolicy,
+					RecoverPanics: true,
+				},
+			},
+		},
+		{
+			name: "factories_struct",
+			params: di.GenerationParameters{
+				TestSupport: true,
+				Factories:   di.FactoriesParameters{Struct: "Factories"},
+			},
+		},
+		{
+			name:     "factories_struct_with_explicit_errors",
+			fixtures: []string{"factories_struct"},
+			params: di.GenerationParameters{
+				TestSupport:   true,
+				Factories:     di.FactoriesParameters{Struct: "Factories"},
+				ErrorHandling: di.ErrorHandling{Explicit: true},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := os.CopyFS(dir, os.DirFS("./testdata/race/module"))
+			require.NoError(t, err, "copy test module")
+			for _, fixture := range append(test.fixtures, test.name) {
+				copyFixture(t, dir, "./testdata/race/"+fixture)
+			}
+
+			generator := &di.Generator{
+				BaseDir:    "di",
+				ModulePath: "example.com/test",
+				FS:         afero.NewBasePathFs(afero.NewOsFs(), dir),
+				Params:     test.params,
+			}
+			err = generator.Generate()
+			require.NoError(t, err)
+
+			command := exec.Command(goBinary, "test", "-race", "-count=1", "./...")
+			command.Dir = dir
+			command.Env = append(os.Environ(), "CGO_ENABLED=1", "GOWORK=off", "GOFLAGS=-mod=mod")
+			output, err := command.CombinedOutput()
+			require.NoError(t, err, "generated container tests failed:\n%s", output)
+		})
+	}
+}
+
+// copyFixture copies files of the fixture dir over the test module, existing files are replaced.
+func copyFixture(t *testing.T, dir, fixture string) {
+	t.Helper()
+
+	if _, err := os.Stat(fixture); os.IsNotExist(err) {
+		return
+	}
+	err := filepath.WalkDir(fixture, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(fixture, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(target, content, 0644)
+	})
+	require.NoError(t, err, "copy fixture %s", fixture)
+}
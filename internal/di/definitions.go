@@ -14,17 +14,20 @@ type RootContainerDefinition struct {
 	Services   []*ServiceDefinition
 	Containers []*ContainerDefinition
 	Factories  map[string]*FactoryDefinition
+	// FactoryTypes are names of types declared in the factories packages.
+	FactoryTypes []string
 }
 
 func (c RootContainerDefinition) Type(definition TypeDefinition) func(statement *jen.Statement) {
 	return func(statement *jen.Statement) {
 		packageName := c.PackageName(definition)
+		if definition.IsMap() {
+			statement = statement.Map(jen.Do(c.Type(*definition.Key)))
+		}
 		if definition.IsPointer {
 			statement = statement.Op("*")
 		} else if definition.IsSlice {
 			statement = statement.Op("[]")
-		} else if definition.IsMap() {
-			statement = statement.Map(jen.Id(definition.Key.Name))
 		}
 		statement.Qual(packageName, definition.Name)
 	}
@@ -38,6 +41,24 @@ func (c RootContainerDefinition) PackageName(definition TypeDefinition) string {
 	return ""
 }
 
+// HasService returns true if any service of the root or attached containers matches.
+func (c RootContainerDefinition) HasService(match func(service *ServiceDefinition) bool) bool {
+	for _, service := range c.Services {
+		if match(service) {
+			return true
+		}
+	}
+	for _, container := range c.Containers {
+		for _, service := range container.Services {
+			if match(service) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func (c RootContainerDefinition) ServicesCount() int {
 	count := len(c.Services)
 
@@ -76,8 +97,37 @@ type ServiceDefinition struct {
 	HasCloser  bool // "close" tag - generate closer method call
 	IsRequired bool // "required" tag - will generate argument for public container constructor
 	IsPublic   bool // "public" tag - will generate getter for public container
+	IsNamed    bool // "named" tag - service is available by name via Get method of public container
+
+	CloseMethod string // "close=Method" tag - name of the closer method, "Close" by default
+	StartMethod string // "start=Method" tag - name of the method to start long-running service
+	StopMethod  string // "stop=Method" tag - name of the method to stop long-running service
+	// Methods are signatures of close, start and stop methods by names, signatures are parsed
+	// from the options ("close=Shutdown(ctx) error"), "Method() error" by default.
+	// In type-checked mode signatures are resolved by MethodResolver.
+	Methods map[string]MethodSignature
+
+	EnvVar     string // "env=VAR" tag - service is parsed from the environment variable instead of the factory
+	Default    string // "default=value" tag - value used when the environment variable is not set
+	HasDefault bool
+	Value      string // "value=literal" tag - service is set to the literal instead of the factory
+	HasValue   bool
+
+	From         string             // "from=Service.Field" tag - service is read from the field of another service
+	Source       *ServiceDefinition // service resolved by the first part of "from" tag
+	SourceFields []string           // path to the field of the source service
 }
 
+// MethodSignature is the signature of the close, start or stop method of the service.
+// Supported methods have an optional context argument and an optional error result.
+type MethodSignature struct {
+	WithContext  bool
+	ReturnsError bool
+}
+
+// defaultMethodSignature is the signature of the method without signature in the option, like io.Closer.
+var defaultMethodSignature = MethodSignature{ReturnsError: true}
+
 func (s ServiceDefinition) ID() string {
 	id := "id_"
 	if s.Prefix != "" {
@@ -88,10 +138,30 @@ func (s ServiceDefinition) ID() string {
 	return id
 }
 
+// IsRunnable returns true if the service is long-running and has to be started by public container Run method.
+func (s ServiceDefinition) IsRunnable() bool {
+	return s.StartMethod != ""
+}
+
+// HasFactory returns true if the service is created by the factory.
+func (s ServiceDefinition) HasFactory() bool {
+	return !s.IsRequired && s.EnvVar == "" && !s.HasValue && s.From == ""
+}
+
 func (s ServiceDefinition) Title() string {
 	return strings.Title(s.Name)
 }
 
+// FullName returns the service name qualified by the name of attached container,
+// for example "Repositories.EntityRepository".
+func (s ServiceDefinition) FullName() string {
+	if s.Prefix != "" {
+		return s.Prefix + "." + s.Title()
+	}
+
+	return s.Title()
+}
+
 func (s ServiceDefinition) PublicTitle() string {
 	if s.PublicName != "" {
 		return strings.Title(s.PublicName)
@@ -155,11 +225,31 @@ func (d TypeDefinition) IsURL() bool {
 	return d.Package == "url" && d.Name == "URL"
 }
 
+// IsParsable returns true if the value of the type can be parsed from a string:
+// basic types, durations, URLs and slices of basic types.
+func (d TypeDefinition) IsParsable() bool {
+	switch {
+	case d.IsMap():
+		return false
+	case d.IsSlice:
+		return d.IsBasicType()
+	case d.IsPointer:
+		return d.IsURL()
+	}
+
+	return d.IsBasicType() || d.IsDuration()
+}
+
 func (d TypeDefinition) String() string {
 	var s strings.Builder
 
+	if d.IsMap() {
+		s.WriteString("map[" + d.Key.String() + "]")
+	}
 	if d.IsPointer {
 		s.WriteString("*")
+	} else if d.IsSlice {
+		s.WriteString("[]")
 	}
 	s.WriteString(d.Package)
 	if d.Package != "" {
@@ -173,6 +263,7 @@ func (d TypeDefinition) String() string {
 type FactoryDefinitions struct {
 	Imports   map[string]*ImportDefinition
 	Factories map[string]*FactoryDefinition
+	Types     []string
 }
 
 func NewFactoryDefinitions() *FactoryDefinitions {
@@ -189,9 +280,32 @@ func (d *FactoryDefinitions) merge(df *FactoryDefinitions) {
 	for k, v := range df.Imports {
 		d.Imports[k] = v
 	}
+	d.Types = append(d.Types, df.Types...)
 }
 
 type FactoryDefinition struct {
 	Name         string
 	ReturnsError bool
+	// Calls are names of getters called on the lookup container in order of the first call,
+	// getters of attached containers are qualified by the container name, for example "Repositories.EntityRepository".
+	Calls []string
+	// Params are parameters of the factory in order of declaration.
+	Params []FactoryParam
+}
+
+// FactoryParamKind defines the argument passed by the container into the factory parameter.
+type FactoryParamKind int
+
+const (
+	ContextParam FactoryParamKind = iota
+	ContainerParam
+	// DependencyParam receives the service resolved by the name of the parameter.
+	DependencyParam
+)
+
+type FactoryParam struct {
+	Name string
+	Kind FactoryParamKind
+	// Service is the dependency resolved for DependencyParam.
+	Service *ServiceDefinition
 }
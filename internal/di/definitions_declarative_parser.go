@@ -0,0 +1,419 @@
+package di
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/muonsoft/errors"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// DefinitionsSchema is the JSON schema of declarative definitions. Fields of definitions are checked
+// by properties of the schema, so the parser and the schema are always in sync.
+//
+//go:embed schema/definitions.schema.json
+var DefinitionsSchema []byte
+
+// definitionsFields are the known fields of definitions, containers and services taken from DefinitionsSchema.
+var definitionsFields = mustParseSchemaFields(DefinitionsSchema)
+
+func mustParseSchemaFields(schema []byte) map[string][]string {
+	var document struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Defs       map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(schema, &document); err != nil {
+		panic(fmt.Sprintf("parse definitions schema: %s", err))
+	}
+
+	return map[string][]string{
+		"definitions": slices.Sorted(maps.Keys(document.Properties)),
+		"container":   slices.Sorted(maps.Keys(document.Defs["container"].Properties)),
+		"service":     slices.Sorted(maps.Keys(document.Defs["service"].Properties)),
+	}
+}
+
+// DeclarativeDefinitionsParser parses YAML or JSON definitions of the container. It is an alternative
+// to the Go struct definitions for containers generated from other sources, for example from a service catalog.
+//
+//	imports:
+//	  domain: example.com/project/domain
+//	services:
+//	  - name: Config
+//	    type: domain.Config
+//	    required: true
+//	containers:
+//	  - name: Repositories
+//	    services:
+//	      - name: EntityRepository
+//	        type: domain.EntityRepository
+//	        public: true
+type DeclarativeDefinitionsParser struct {
+	fs     afero.Fs
+	logger Logger
+}
+
+func NewDeclarativeDefinitionsParser(fs afero.Fs, logger Logger) *DeclarativeDefinitionsParser {
+	return &DeclarativeDefinitionsParser{fs: fs, logger: logger}
+}
+
+// IsDeclarativeDefinitionsFile returns true if the definitions file is in YAML or JSON format.
+func IsDeclarativeDefinitionsFile(filename string) bool {
+	switch path.Ext(filename) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+
+	return false
+}
+
+func (p *DeclarativeDefinitionsParser) ParseFile(filename string) (*RootContainerDefinition, error) {
+	data, err := afero.ReadFile(p.fs, filename)
+	if err != nil {
+		return nil, errors.Errorf("read file: %w", err)
+	}
+
+	return p.parse(filename, data)
+}
+
+func (p *DeclarativeDefinitionsParser) ParseSource(source string) (*RootContainerDefinition, error) {
+	return p.parse("definitions", []byte(source))
+}
+
+func (p *DeclarativeDefinitionsParser) parse(filename string, data []byte) (*RootContainerDefinition, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, errors.Errorf("%w: %s: %w", ErrParsing, filename, err)
+	}
+	if len(document.Content) == 0 {
+		return nil, errors.Errorf("%w: %s: empty definitions", ErrInvalidDefinition, filename)
+	}
+
+	state := &declarativeParsing{filename: filename, logger: p.logger}
+	container, err := state.parseRoot(document.Content[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveSources(container); err != nil {
+		return nil, errors.Errorf("parse definitions: %w", err)
+	}
+
+	return container, nil
+}
+
+type declarativeParsing struct {
+	filename string
+	logger   Logger
+	imports  map[string]*ImportDefinition
+}
+
+func (p *declarativeParsing) parseRoot(node *yaml.Node) (*RootContainerDefinition, error) {
+	fields, err := p.mapping(node, "definitions")
+	if err != nil {
+		return nil, err
+	}
+
+	p.imports, err = p.parseImports(fields["imports"])
+	if err != nil {
+		return nil, err
+	}
+	services, err := p.parseServices(fields["services"], "")
+	if err != nil {
+		return nil, err
+	}
+	containers, err := p.parseContainers(fields["containers"])
+	if err != nil {
+		return nil, err
+	}
+
+	return &RootContainerDefinition{
+		Name:       "Container",
+		Package:    "definitions",
+		Imports:    p.imports,
+		Services:   services,
+		Containers: containers,
+		Factories:  make(map[string]*FactoryDefinition, 0),
+	}, nil
+}
+
+// parseImports parses import paths by aliases used in types of services.
+func (p *declarativeParsing) parseImports(node *yaml.Node) (map[string]*ImportDefinition, error) {
+	imports := make(map[string]*ImportDefinition)
+	if node == nil {
+		return imports, nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, p.errorf(node, "imports must be a mapping of aliases to import paths")
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		alias, importPath := node.Content[i], node.Content[i+1]
+		if !token.IsIdentifier(alias.Value) {
+			return nil, p.errorf(alias, "invalid import alias %q", alias.Value)
+		}
+		if importPath.Kind != yaml.ScalarNode || importPath.Value == "" {
+			return nil, p.errorf(importPath, "import path of %q must be a string", alias.Value)
+		}
+		definition := &ImportDefinition{ID: alias.Value, Path: importPath.Value}
+		if path.Base(importPath.Value) != alias.Value {
+			definition.Name = alias.Value
+		}
+		imports[alias.Value] = definition
+	}
+
+	return imports, nil
+}
+
+func (p *declarativeParsing) parseContainers(node *yaml.Node) ([]*ContainerDefinition, error) {
+	containers := make([]*ContainerDefinition, 0)
+	if node == nil {
+		return containers, nil
+	}
+	if node.Kind != yaml.SequenceNode {
+		return nil, p.errorf(node, "containers must be a list")
+	}
+
+	names := make(map[string]bool, len(node.Content))
+	for _, item := range node.Content {
+		fields, err := p.mapping(item, "container")
+		if err != nil {
+			return nil, err
+		}
+		name, err := p.identifier(item, fields, "container")
+		if err != nil {
+			return nil, err
+		}
+		if names[name] {
+			return nil, p.errorf(item, "duplicate container %q", name)
+		}
+		names[name] = true
+
+		typeName := strings.Title(name) + "Container"
+		if fields["type"] != nil {
+			typeName, err = p.string(fields["type"], "type")
+			if err != nil {
+				return nil, err
+			}
+			if !token.IsIdentifier(typeName) {
+				return nil, p.errorf(fields["type"], "invalid container type %q", typeName)
+			}
+		}
+
+		services, err := p.parseServices(fields["services"], name)
+		if err != nil {
+			return nil, err
+		}
+		if len(services) == 0 {
+			return nil, p.errorf(item, "container %s must not be empty", name)
+		}
+
+		containers = append(containers, &ContainerDefinition{
+			Name:     name,
+			Type:     TypeDefinition{Package: "internal", Name: typeName},
+			Services: services,
+		})
+	}
+
+	return containers, nil
+}
+
+func (p *declarativeParsing) parseServices(node *yaml.Node, prefix string) ([]*ServiceDefinition, error) {
+	services := make([]*ServiceDefinition, 0)
+	if node == nil {
+		return services, nil
+	}
+	if node.Kind != yaml.SequenceNode {
+		return nil, p.errorf(node, "services must be a list")
+	}
+
+	names := make(map[string]bool, len(node.Content))
+	for _, item := range node.Content {
+		service, err := p.parseService(item)
+		if err != nil {
+			return nil, err
+		}
+		if names[service.Name] {
+			return nil, p.errorf(item, "duplicate service %q", service.Name)
+		}
+		names[service.Name] = true
+		service.Prefix = prefix
+		services = append(services, service)
+	}
+
+	return services, nil
+}
+
+// serviceFlags are the options of "di" tag presented by fields of declarative definitions.
+// Boolean fields enable flags, other fields set values of options like "close=Shutdown".
+var serviceFlags = []struct {
+	name   string
+	isBool bool
+}{
+	{name: "set", isBool: true},
+	{name: "close"},
+	{name: "start"},
+	{name: "stop"},
+	{name: "required", isBool: true},
+	{name: "public", isBool: true},
+	{name: "named", isBool: true},
+	{name: "env"},
+	{name: "default"},
+	{name: "value"},
+	{name: "from"},
+}
+
+func (p *declarativeParsing) parseService(node *yaml.Node) (*ServiceDefinition, error) {
+	fields, err := p.mapping(node, "service")
+	if err != nil {
+		return nil, err
+	}
+	name, err := p.identifier(node, fields, "service")
+	if err != nil {
+		return nil, err
+	}
+	typeDef, err := p.parseType(node, fields["type"], name)
+	if err != nil {
+		return nil, err
+	}
+
+	options := ServiceDefinitionsOptions{}
+	for _, flag := range serviceFlags {
+		value := fields[flag.name]
+		if value == nil {
+			continue
+		}
+		switch {
+		case flag.isBool || flag.name == "close" && value.Tag == "!!bool":
+			enabled, err := p.bool(value, flag.name)
+			if err != nil {
+				return nil, err
+			}
+			if enabled {
+				options.Flags = append(options.Flags, flag.name)
+			}
+		case value.Kind == yaml.ScalarNode:
+			options.Flags = append(options.Flags, flag.name+"="+value.Value)
+		default:
+			return nil, p.errorf(value, "field %q of service %s must be a scalar", flag.name, name)
+		}
+	}
+	if options.PublicName, err = p.optionalString(fields["publicName"], "publicName"); err != nil {
+		return nil, err
+	}
+	if options.FactoryPackage, err = p.optionalString(fields["factoryPackage"], "factoryPackage"); err != nil {
+		return nil, err
+	}
+	if options.FactoryFilename, err = p.optionalString(fields["factoryFile"], "factoryFile"); err != nil {
+		return nil, err
+	}
+
+	service, err := newServiceDefinition(p.logger, name, typeDef, options)
+	if err != nil {
+		return nil, errors.Errorf("%s:%d: %w", p.filename, node.Line, err)
+	}
+
+	return service, nil
+}
+
+// parseType parses the Go type expression, packages of the type must be declared in imports.
+func (p *declarativeParsing) parseType(service, node *yaml.Node, name string) (TypeDefinition, error) {
+	if node == nil {
+		return TypeDefinition{}, p.errorf(service, "missing type of service %s", name)
+	}
+	expression, err := p.string(node, "type")
+	if err != nil {
+		return TypeDefinition{}, err
+	}
+	expr, err := parser.ParseExpr(expression)
+	if err != nil {
+		return TypeDefinition{}, p.errorf(node, "invalid type %q of service %s", expression, name)
+	}
+	definition, err := parseTypeDefinition(expr)
+	if err != nil {
+		return TypeDefinition{}, p.errorf(node, "invalid type %q of service %s: %s", expression, name, err)
+	}
+
+	for _, t := range []*TypeDefinition{&definition, definition.Key} {
+		if t != nil && t.Package != "" && p.imports[t.Package] == nil {
+			return TypeDefinition{}, p.errorf(node, "unknown package %s in type of service %s, add it to imports", t.Package, name)
+		}
+	}
+
+	return definition, nil
+}
+
+// mapping checks that the node is a mapping with fields known by the schema only and returns values by field names.
+func (p *declarativeParsing) mapping(node *yaml.Node, kind string) (map[string]*yaml.Node, error) {
+	known := definitionsFields[kind]
+	if node.Kind != yaml.MappingNode {
+		return nil, p.errorf(node, "%s must be a mapping", kind)
+	}
+
+	fields := make(map[string]*yaml.Node, len(node.Content)/2)
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if !slices.Contains(known, key.Value) {
+			return nil, p.errorf(key, "unknown field %q of %s", key.Value, kind)
+		}
+		if fields[key.Value] != nil {
+			return nil, p.errorf(key, "duplicate field %q of %s", key.Value, kind)
+		}
+		fields[key.Value] = value
+	}
+
+	return fields, nil
+}
+
+func (p *declarativeParsing) identifier(node *yaml.Node, fields map[string]*yaml.Node, kind string) (string, error) {
+	if fields["name"] == nil {
+		return "", p.errorf(node, "missing name of %s", kind)
+	}
+	name, err := p.string(fields["name"], "name")
+	if err != nil {
+		return "", err
+	}
+	if !token.IsIdentifier(name) {
+		return "", p.errorf(fields["name"], "invalid name %q of %s", name, kind)
+	}
+
+	return name, nil
+}
+
+func (p *declarativeParsing) string(node *yaml.Node, field string) (string, error) {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!str" {
+		return "", p.errorf(node, "field %q must be a string", field)
+	}
+
+	return node.Value, nil
+}
+
+func (p *declarativeParsing) optionalString(node *yaml.Node, field string) (string, error) {
+	if node == nil {
+		return "", nil
+	}
+
+	return p.string(node, field)
+}
+
+func (p *declarativeParsing) bool(node *yaml.Node, field string) (bool, error) {
+	var value bool
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" || node.Decode(&value) != nil {
+		return false, p.errorf(node, "field %q must be a boolean", field)
+	}
+
+	return value, nil
+}
+
+func (p *declarativeParsing) errorf(node *yaml.Node, format string, args ...any) error {
+	return errors.Errorf("%w: %s:%d: %s", ErrInvalidDefinition, p.filename, node.Line, fmt.Sprintf(format, args...))
+}
@@ -63,24 +63,28 @@ func (p OptionsParser) parseServiceDefinitionsOptionsFromComments(field *ast.Fie
 
 	for _, comment := range field.Doc.List {
 		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
-		if strings.HasPrefix(text, "di:") {
-			parts := split(text, ":")
-			if len(parts) == 2 {
-				options.Flags = append(options.Flags, split(parts[1], ",")...)
-			} else if len(parts) == 3 {
-				switch parts[1] {
-				case "public_name":
-					options.PublicName = parts[2]
-				case "factory_pkg":
-					options.FactoryPackage = parts[2]
-				case "factory_file":
-					options.FactoryFilename = parts[2]
-				default:
-					p.Logger.Warning("unknown comment service definition option:", parts[1])
-				}
-			} else {
-				p.Logger.Warning("cannot parse comment service definition option:", text)
-			}
+		option, found := strings.CutPrefix(text, "di:")
+		if !found {
+			continue
+		}
+		// values of flags may contain colons, for example "di:value=http://example.com",
+		// so only the name of named option is separated by colon
+		name, value, isNamed := strings.Cut(option, ":")
+		if !isNamed || strings.ContainsAny(name, "=,") {
+			options.Flags = append(options.Flags, split(option, ",")...)
+			continue
+		}
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		switch name {
+		case "public_name":
+			options.PublicName = value
+		case "factory_pkg":
+			options.FactoryPackage = value
+		case "factory_file":
+			options.FactoryFilename = value
+		default:
+			p.Logger.Warning("unknown comment service definition option:", name)
 		}
 	}
 
@@ -115,6 +115,15 @@ func TestOptionsParser_ParseServiceDefinitionOptions(t *testing.T) {
 				Flags: []string{"set", "close"},
 			},
 		},
+		{
+			name: "comments: flag with colons in value",
+			field: &ast.Field{
+				Doc: &ast.CommentGroup{List: []*ast.Comment{{Text: "// di: public,value=http://example.com:8080"}}},
+			},
+			want: di.ServiceDefinitionsOptions{
+				Flags: []string{"public", "value=http://example.com:8080"},
+			},
+		},
 		{
 			name: "comments: public name",
 			field: &ast.Field{
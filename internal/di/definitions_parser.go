@@ -1,7 +1,9 @@
 package di
 
 import (
+	"cmp"
 	"go/ast"
+	"regexp"
 	"strings"
 
 	"github.com/muonsoft/errors"
@@ -63,6 +65,9 @@ func (p *DefinitionsParser) parseContainerAST(file *ast.File) (*RootContainerDef
 		Containers: containers,
 		Factories:  make(map[string]*FactoryDefinition, 0),
 	}
+	if err := resolveSources(definition); err != nil {
+		return nil, errors.Errorf("parse definitions: %w", err)
+	}
 
 	return definition, nil
 }
@@ -105,7 +110,10 @@ func (p *DefinitionsParser) parseDefinitions(container *ast.StructType) ([]*Serv
 			}
 			containers = append(containers, internalContainer)
 		} else {
-			service := p.createServiceDefinition(field, fieldType)
+			service, err := p.createServiceDefinition(field, fieldType)
+			if err != nil {
+				return nil, nil, err
+			}
 			services = append(services, service)
 		}
 	}
@@ -152,10 +160,20 @@ func (p *DefinitionsParser) createContainerDefinition(field *ast.Field) (*Contai
 	return definition, nil
 }
 
-func (p *DefinitionsParser) createServiceDefinition(field *ast.Field, typeDef TypeDefinition) *ServiceDefinition {
-	name := parseFieldName(field)
+func (p *DefinitionsParser) createServiceDefinition(field *ast.Field, typeDef TypeDefinition) (*ServiceDefinition, error) {
 	options := OptionsParser{Logger: p.logger}.ParseServiceDefinitionOptions(field)
 
+	return newServiceDefinition(p.logger, parseFieldName(field), typeDef, options)
+}
+
+// newServiceDefinition creates the service definition from parsed options. It is shared by parsers
+// of Go and declarative definitions.
+func newServiceDefinition(
+	logger Logger,
+	name string,
+	typeDef TypeDefinition,
+	options ServiceDefinitionsOptions,
+) (*ServiceDefinition, error) {
 	definition := &ServiceDefinition{
 		Name:            name,
 		Type:            typeDef,
@@ -167,22 +185,152 @@ func (p *DefinitionsParser) createServiceDefinition(field *ast.Field, typeDef Ty
 		definition.FactoryFileName += ".go"
 	}
 
+	var err error
 	for _, flag := range options.Flags {
-		switch flag {
+		name, value, _ := strings.Cut(flag, "=")
+		switch name {
 		case "set":
 			definition.HasSetter = true
 		case "close":
 			definition.HasCloser = true
+			definition.CloseMethod, err = addServiceMethod(definition, name, cmp.Or(value, "Close"))
+		case "start":
+			definition.StartMethod, err = addServiceMethod(definition, name, value)
+		case "stop":
+			definition.StopMethod, err = addServiceMethod(definition, name, value)
 		case "required":
 			definition.IsRequired = true
 		case "public":
 			definition.IsPublic = true
+		case "named":
+			definition.IsNamed = true
+		case "env":
+			definition.EnvVar = value
+		case "default":
+			definition.Default = value
+			definition.HasDefault = true
+			if typeDef.IsSlice {
+				definition.Default = listFromTag(value)
+			}
+		case "value":
+			definition.Value = value
+			definition.HasValue = true
+		case "from":
+			definition.From = value
 		default:
-			p.logger.Warning("unknown service definition option:", flag)
+			logger.Warning("unknown service definition option:", flag)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if definition.StopMethod != "" && definition.StartMethod == "" {
+		logger.Warning("stop option is ignored without start option for service:", name)
+		definition.StopMethod = ""
+	}
+	if definition.From != "" && (definition.IsRequired || definition.EnvVar != "" || definition.HasValue) {
+		return nil, errors.Errorf(
+			"%w: service %s: from option cannot be used with required, env or value options",
+			ErrInvalidDefinition, name,
+		)
+	}
+	if definition.HasValue {
+		if err := validateValueService(definition); err != nil {
+			return nil, err
 		}
+	} else if definition.EnvVar != "" {
+		if err := validateEnvService(definition); err != nil {
+			return nil, err
+		}
+	}
+	if definition.HasDefault && definition.EnvVar == "" {
+		logger.Warning("default option is ignored without env option for service:", name)
+		definition.Default = ""
+		definition.HasDefault = false
+	}
+
+	return definition, nil
+}
+
+// methodOption is the value of close, start and stop options: the name of the method
+// with the optional signature, for example "Shutdown" or "Shutdown(ctx) error".
+var methodOption = regexp.MustCompile(`^(\w+)(\((ctx)?\)\s*(error)?)?$`)
+
+// addServiceMethod parses the value of the method option and adds the signature of the method
+// to the service, the method without signature is called as "Method() error".
+func addServiceMethod(service *ServiceDefinition, option, value string) (string, error) {
+	match := methodOption.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return "", errors.Errorf(
+			"%w: service %s: invalid method %q of %s option, it must be a name with the optional signature, "+
+				"for example \"Shutdown(ctx) error\"",
+			ErrInvalidDefinition, service.Name, value, option,
+		)
 	}
+	signature := defaultMethodSignature
+	if match[2] != "" {
+		signature = MethodSignature{WithContext: match[3] != "", ReturnsError: match[4] != ""}
+	}
+	if service.Methods == nil {
+		service.Methods = make(map[string]MethodSignature, 1)
+	}
+	service.Methods[match[1]] = signature
 
-	return definition
+	return match[1], nil
+}
+
+func validateValueService(service *ServiceDefinition) error {
+	if service.IsRequired {
+		return errors.Errorf(
+			"%w: service %s: value option cannot be used with required option",
+			ErrInvalidDefinition, service.Name,
+		)
+	}
+	if service.EnvVar != "" {
+		return errors.Errorf(
+			"%w: service %s: value option cannot be used with env option, use default option instead",
+			ErrInvalidDefinition, service.Name,
+		)
+	}
+	if service.Type.IsSlice || !service.Type.IsParsable() {
+		return errors.Errorf(
+			"%w: service %s: type %s cannot be set by value",
+			ErrNotSupported, service.Name, service.Type,
+		)
+	}
+	if err := validateLiteral(service.Type, service.Value); err != nil {
+		return errors.Errorf(
+			"%w: service %s: invalid value %q: %w",
+			ErrInvalidDefinition, service.Name, service.Value, err,
+		)
+	}
+
+	return nil
+}
+
+func validateEnvService(service *ServiceDefinition) error {
+	if service.IsRequired {
+		return errors.Errorf(
+			"%w: service %s: env option cannot be used with required option",
+			ErrInvalidDefinition, service.Name,
+		)
+	}
+	if !service.Type.IsParsable() {
+		return errors.Errorf(
+			"%w: service %s: type %s cannot be parsed from environment variable",
+			ErrNotSupported, service.Name, service.Type,
+		)
+	}
+	if service.HasDefault {
+		if err := validateLiteral(service.Type, service.Default); err != nil {
+			return errors.Errorf(
+				"%w: service %s: invalid default value %q: %w",
+				ErrInvalidDefinition, service.Name, service.Default, err,
+			)
+		}
+	}
+
+	return nil
 }
 
 func (p *DefinitionsParser) parseServiceDefinitions(container *ast.StructType, path string) ([]*ServiceDefinition, error) {
@@ -202,7 +350,10 @@ func (p *DefinitionsParser) parseServiceDefinitions(container *ast.StructType, p
 			return nil, errors.Errorf("%w: %s", ErrNotSupported, "container inside container")
 		}
 
-		service := p.createServiceDefinition(field, fieldType)
+		service, err := p.createServiceDefinition(field, fieldType)
+		if err != nil {
+			return nil, err
+		}
 		service.Prefix = path
 		services = append(services, service)
 	}
@@ -301,6 +452,64 @@ func parseTypeDefinition(expr ast.Expr) (TypeDefinition, error) {
 	return TypeDefinition{}, errors.Errorf("%w: %s", ErrUnexpectedType, "parse type")
 }
 
+// resolveSources resolves services and field paths of "from" options. The path starts
+// with the name of the service, qualified by the name of attached container if needed.
+func resolveSources(container *RootContainerDefinition) error {
+	services := make(map[string]*ServiceDefinition, container.ServicesCount())
+	bindings := make([]*ServiceDefinition, 0)
+	add := func(service *ServiceDefinition) {
+		services[service.FullName()] = service
+		if service.From != "" {
+			bindings = append(bindings, service)
+		}
+	}
+	for _, service := range container.Services {
+		add(service)
+	}
+	for _, attachedContainer := range container.Containers {
+		for _, service := range attachedContainer.Services {
+			add(service)
+		}
+	}
+
+	for _, service := range bindings {
+		path := strings.Split(service.From, ".")
+		source, fields := services[path[0]], path[1:]
+		if source == nil && len(path) > 1 {
+			source, fields = services[path[0]+"."+path[1]], path[2:]
+		}
+		if source == nil {
+			return errors.Errorf(
+				"%w: service %s: from option: source service of %q not found",
+				ErrInvalidDefinition, service.FullName(), service.From,
+			)
+		}
+		if len(fields) == 0 {
+			return errors.Errorf(
+				"%w: service %s: from option: missing field of %s service",
+				ErrInvalidDefinition, service.FullName(), source.FullName(),
+			)
+		}
+		service.Source = source
+		service.SourceFields = fields
+	}
+
+	for _, service := range bindings {
+		visited := map[*ServiceDefinition]bool{service: true}
+		for source := service.Source; source != nil; source = source.Source {
+			if visited[source] {
+				return errors.Errorf(
+					"%w: service %s: from option: circular reference",
+					ErrInvalidDefinition, service.FullName(),
+				)
+			}
+			visited[source] = true
+		}
+	}
+
+	return nil
+}
+
 func validateInternalContainer(container *ast.StructType) error {
 	if len(container.Fields.List) == 0 {
 		return errors.Errorf("%w: %s", ErrInvalidDefinition, "container must not be empty")
@@ -2,8 +2,12 @@ package di_test
 
 import (
 	_ "embed"
+	"encoding/json"
+	"maps"
+	"slices"
 	"testing"
 
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/strider2038/digen/internal/di"
@@ -27,3 +31,283 @@ func TestParseFactoryFromSource(t *testing.T) {
 	assert.Contains(t, factory.Factories, "UseCase")
 	assert.Contains(t, factory.Factories, "Handler")
 }
+
+func TestDefinitionsParser_ParseSource_ParameterServices(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   string
+		wantErr string
+	}{
+		{
+			name:  "slice default separated by semicolons",
+			field: "Hosts []string `di:\"env=HOSTS,default=a;b\"`",
+		},
+		{
+			name:  "source in the same container",
+			field: "URL *url.URL `di:\"required\"`\n\tHost string `di:\"from=URL.Host\"`",
+		},
+		{
+			name:  "url without default",
+			field: "URL *url.URL `di:\"env=URL\"`",
+		},
+		{
+			name:    "unsupported type",
+			field:   "Server *http.Server `di:\"env=SERVER\"`",
+			wantErr: "parse definitions: not supported: service Server: type *http.Server cannot be parsed from environment variable",
+		},
+		{
+			name:    "url without pointer",
+			field:   "URL url.URL `di:\"env=URL\"`",
+			wantErr: "parse definitions: not supported: service URL: type url.URL cannot be parsed from environment variable",
+		},
+		{
+			name:    "invalid default",
+			field:   "Port int `di:\"env=PORT,default=http\"`",
+			wantErr: `parse definitions: invalid definition: service Port: invalid default value "http": strconv.ParseInt: parsing "http": invalid syntax`,
+		},
+		{
+			name:    "invalid default item",
+			field:   "Ports []uint8 `di:\"env=PORTS,default=80;300\"`",
+			wantErr: `parse definitions: invalid definition: service Ports: invalid default value "80,300": strconv.ParseUint: parsing "300": value out of range`,
+		},
+		{
+			name:  "literal duration",
+			field: "Timeout time.Duration `di:\"value=1m30s\"`",
+		},
+		{
+			name:    "invalid literal",
+			field:   "Timeout time.Duration `di:\"value=5\"`",
+			wantErr: `parse definitions: invalid definition: service Timeout: invalid value "5": time: missing unit in duration "5"`,
+		},
+		{
+			name:    "literal slice",
+			field:   "Hosts []string `di:\"value=a\"`",
+			wantErr: "parse definitions: not supported: service Hosts: type []string cannot be set by value",
+		},
+		{
+			name:    "literal with env",
+			field:   "Port int `di:\"env=PORT,value=80\"`",
+			wantErr: "parse definitions: invalid definition: service Port: value option cannot be used with env option, use default option instead",
+		},
+		{
+			name:    "source not found",
+			field:   "Port int `di:\"from=Config.HTTP.Port\"`",
+			wantErr: `parse definitions: invalid definition: service Port: from option: source service of "Config.HTTP.Port" not found`,
+		},
+		{
+			name:    "source without field",
+			field:   "URL *url.URL `di:\"required\"`\n\tBaseURL *url.URL `di:\"from=URL\"`",
+			wantErr: "parse definitions: invalid definition: service BaseURL: from option: missing field of URL service",
+		},
+		{
+			name:    "circular source",
+			field:   "A *url.URL `di:\"from=B.User\"`\n\tB *url.URL `di:\"from=A.User\"`",
+			wantErr: "parse definitions: invalid definition: service A: from option: circular reference",
+		},
+		{
+			name:    "source with env",
+			field:   "Port int `di:\"from=Config.Port,env=PORT\"`",
+			wantErr: "parse definitions: invalid definition: service Port: from option cannot be used with required, env or value options",
+		},
+		{
+			name:    "required service",
+			field:   "Port int `di:\"env=PORT,required\"`",
+			wantErr: "parse definitions: invalid definition: service Port: env option cannot be used with required option",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parser := di.NewDefinitionsParser(afero.NewMemMapFs(), &testingLogger{tb: t})
+			source := "package definitions\n" +
+				"import (\n\t\"net/http\"\n\t\"net/url\"\n\t\"time\"\n)\n" +
+				"type Container struct {\n\t" + test.field + "\n}\n"
+
+			container, err := parser.ParseSource(source)
+
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.NotEmpty(t, container.Services)
+			}
+		})
+	}
+}
+
+func TestDeclarativeDefinitionsParser_ParseSource_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{
+			name:    "unknown field",
+			source:  "services:\n  - name: Config\n    type: string\n    pubic: true\n",
+			wantErr: `invalid definition: definitions:4: unknown field "pubic" of service`,
+		},
+		{
+			name:    "missing type",
+			source:  "services:\n  - name: Config\n",
+			wantErr: "invalid definition: definitions:2: missing type of service Config",
+		},
+		{
+			name:    "unknown package",
+			source:  "services:\n  - name: Config\n    type: '*config.Params'\n",
+			wantErr: "invalid definition: definitions:3: unknown package config in type of service Config, add it to imports",
+		},
+		{
+			name:    "not boolean flag",
+			source:  "services:\n  - name: Config\n    type: string\n    public: 'yes'\n",
+			wantErr: `invalid definition: definitions:4: field "public" must be a boolean`,
+		},
+		{
+			name:    "duplicate service",
+			source:  "services:\n  - name: Port\n    type: int\n  - name: Port\n    type: int\n",
+			wantErr: `invalid definition: definitions:4: duplicate service "Port"`,
+		},
+		{
+			name:    "empty container",
+			source:  "containers:\n  - name: Params\n    services: []\n",
+			wantErr: "invalid definition: definitions:2: container Params must not be empty",
+		},
+		{
+			name:    "invalid service option",
+			source:  "services:\n  - name: Port\n    type: int\n    env: PORT\n    default: http\n",
+			wantErr: `definitions:2: invalid definition: service Port: invalid default value "http": strconv.ParseInt: parsing "http": invalid syntax`,
+		},
+		{
+			name:    "invalid syntax",
+			source:  "services: [",
+			wantErr: "parsing error: definitions: yaml: line 1: did not find expected node content",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parser := di.NewDeclarativeDefinitionsParser(afero.NewMemMapFs(), &testingLogger{tb: t})
+
+			_, err := parser.ParseSource(test.source)
+
+			assert.EqualError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestDefinitionsSchema_ServiceProperties(t *testing.T) {
+	// samples of every property of the service in the schema
+	samples := map[string]string{
+		"name":           "",
+		"type":           "",
+		"publicName":     "publicName: HTTPPort",
+		"factoryPackage": "factoryPackage: example.com/test/factories",
+		"factoryFile":    "factoryFile: port",
+		"set":            "set: true",
+		"close":          "close: Shutdown(ctx) error",
+		"start":          "start: Run(ctx) error",
+		"stop":           "start: Run()\n    stop: Stop()",
+		"required":       "required: true",
+		"public":         "public: true",
+		"named":          "named: true",
+		"env":            "env: PORT",
+		"default":        "env: PORT\n    default: 80",
+		"value":          "value: 80",
+		"from":           "from: Config.Port",
+	}
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]struct {
+				Type any `json:"type"`
+			} `json:"properties"`
+		} `json:"$defs"`
+	}
+	err := json.Unmarshal(di.DefinitionsSchema, &schema)
+	require.NoError(t, err)
+	properties := schema.Defs["service"].Properties
+	require.Equal(t, slices.Sorted(maps.Keys(samples)), slices.Sorted(maps.Keys(properties)))
+
+	for name, property := range properties {
+		t.Run(name, func(t *testing.T) {
+			parser := di.NewDeclarativeDefinitionsParser(afero.NewMemMapFs(), &testingLogger{tb: t})
+			source := "imports:\n  config: example.com/test/config\n" +
+				"services:\n  - name: Config\n    type: config.Params\n  - name: Port\n    type: int\n"
+
+			_, err := parser.ParseSource(source + "    " + samples[name] + "\n")
+
+			require.NoError(t, err)
+			if property.Type == "boolean" {
+				_, err := parser.ParseSource(source + "    " + name + ": 'yes'\n")
+				assert.EqualError(t, err, `invalid definition: definitions:8: field "`+name+`" must be a boolean`)
+			}
+		})
+	}
+}
+
+func TestDefinitionsParser_ParseSource_MethodSignatures(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		method  string
+		want    di.MethodSignature
+		wantErr string
+	}{
+		{
+			name:   "default closer",
+			tag:    "close",
+			method: "Close",
+			want:   di.MethodSignature{ReturnsError: true},
+		},
+		{
+			name:   "name without signature",
+			tag:    "close=Shutdown",
+			method: "Shutdown",
+			want:   di.MethodSignature{ReturnsError: true},
+		},
+		{
+			name:   "no arguments and no results",
+			tag:    "close=GracefulStop()",
+			method: "GracefulStop",
+			want:   di.MethodSignature{},
+		},
+		{
+			name:   "context and error",
+			tag:    "stop=Shutdown(ctx) error",
+			method: "Shutdown",
+			want:   di.MethodSignature{WithContext: true, ReturnsError: true},
+		},
+		{
+			name:   "context only",
+			tag:    "start=Run(ctx)",
+			method: "Run",
+			want:   di.MethodSignature{WithContext: true},
+		},
+		{
+			name: "invalid signature",
+			tag:  "close=Shutdown(timeout)",
+			wantErr: `parse definitions: invalid definition: service Server: invalid method "Shutdown(timeout)" of close option, ` +
+				`it must be a name with the optional signature, for example "Shutdown(ctx) error"`,
+		},
+		{
+			name: "missing start method",
+			tag:  "start=",
+			wantErr: `parse definitions: invalid definition: service Server: invalid method "" of start option, ` +
+				`it must be a name with the optional signature, for example "Shutdown(ctx) error"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parser := di.NewDefinitionsParser(afero.NewMemMapFs(), &testingLogger{tb: t})
+			source := "package definitions\n" +
+				"import \"net/http\"\n" +
+				"type Container struct {\n\tServer *http.Server `di:\"" + test.tag + "\"`\n}\n"
+
+			container, err := parser.ParseSource(source)
+
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, container.Services, 1)
+			assert.Equal(t, test.want, container.Services[0].Methods[test.method])
+		})
+	}
+}
@@ -9,6 +9,8 @@ var (
 	ErrParsing           = errors.New("parsing error")
 	ErrFileAlreadyExists = errors.New("file already exists")
 	ErrInvalidDefinition = errors.New("invalid definition")
+	ErrTypeCheck         = errors.New("type check failed")
+	ErrInvalidLayout     = errors.New("invalid package layout")
 
 	errMissingModule = errors.New("cannot detect module from go.mod")
 )
@@ -0,0 +1,280 @@
+package di
+
+import (
+	_ "embed"
+	"slices"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// ExportVersion is the version of the exported document. The major version is increased
+// on incompatible changes of the document, the minor version is increased on new fields.
+const ExportVersion = "1.1"
+
+// ExportSchema is the JSON schema of the exported document.
+//
+//go:embed schema/export.schema.json
+var ExportSchema []byte
+
+// ExportDocument is the parsed container model exported as JSON by "digen export" command.
+type ExportDocument struct {
+	Version string `json:"version"`
+	// Package is the import path of the public container package.
+	Package      string             `json:"package"`
+	Imports      []ExportImport     `json:"imports"`
+	Services     []ExportService    `json:"services"`
+	Containers   []ExportContainer  `json:"containers"`
+	Dependencies []ExportDependency `json:"dependencies"`
+}
+
+type ExportImport struct {
+	Alias string `json:"alias"`
+	Path  string `json:"path"`
+}
+
+type ExportContainer struct {
+	Name     string          `json:"name"`
+	Type     string          `json:"type"`
+	Services []ExportService `json:"services"`
+}
+
+type ExportService struct {
+	// ID is the name of the service qualified by the name of attached container.
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Container   string         `json:"container,omitempty"`
+	Type        ExportType     `json:"type"`
+	PublicName  string         `json:"publicName,omitempty"`
+	Flags       ExportFlags    `json:"flags"`
+	CloseMethod string         `json:"closeMethod,omitempty"`
+	StartMethod string         `json:"startMethod,omitempty"`
+	StopMethod  string         `json:"stopMethod,omitempty"`
+	Env         *ExportEnv     `json:"env,omitempty"`
+	Value       *string        `json:"value,omitempty"`
+	From        string         `json:"from,omitempty"`
+	Factory     *ExportFactory `json:"factory,omitempty"`
+}
+
+type ExportType struct {
+	// Expression is the Go type expression with the package alias, for example "*sql.Connection".
+	Expression string `json:"expression"`
+	// Package is the import path of the type, it is empty for builtin types.
+	Package string      `json:"package,omitempty"`
+	Name    string      `json:"name"`
+	Pointer bool        `json:"pointer,omitempty"`
+	Slice   bool        `json:"slice,omitempty"`
+	Key     *ExportType `json:"key,omitempty"`
+}
+
+type ExportFlags struct {
+	Set      bool `json:"set"`
+	Close    bool `json:"close"`
+	Required bool `json:"required"`
+	Public   bool `json:"public"`
+	Named    bool `json:"named"`
+}
+
+type ExportEnv struct {
+	Name    string  `json:"name"`
+	Default *string `json:"default,omitempty"`
+}
+
+type ExportFactory struct {
+	Package  string `json:"package"`
+	Function string `json:"function"`
+	// Receiver is the type of the factories struct if the factory is its method.
+	Receiver string `json:"receiver,omitempty"`
+	File     string `json:"file"`
+	// Exists is true if the factory is found, otherwise it is generated on the next generation.
+	Exists       bool `json:"exists"`
+	ReturnsError bool `json:"returnsError"`
+}
+
+// ExportDependency is the edge from the service to its dependency. Edges are available for
+// existing factories with dependency parameters or calling getters of the lookup container
+// and for field bindings.
+type ExportDependency struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Kind is "factory" for calls from factories and "binding" for "from" option.
+	Kind string `json:"kind"`
+}
+
+// Exporter builds the exported document from the parsed container model.
+type Exporter struct {
+	fileLocator FileLocator
+	container   *RootContainerDefinition
+	params      GenerationParameters
+}
+
+func NewExporter(fileLocator FileLocator, container *RootContainerDefinition, params GenerationParameters) *Exporter {
+	return &Exporter{fileLocator: fileLocator, container: container, params: params}
+}
+
+func (e *Exporter) Export() *ExportDocument {
+	document := &ExportDocument{
+		Version:      ExportVersion,
+		Package:      e.params.RootPackage,
+		Imports:      make([]ExportImport, 0, len(e.container.Imports)),
+		Services:     make([]ExportService, 0, len(e.container.Services)),
+		Containers:   make([]ExportContainer, 0, len(e.container.Containers)),
+		Dependencies: make([]ExportDependency, 0),
+	}
+
+	for _, imp := range e.container.Imports {
+		document.Imports = append(document.Imports, ExportImport{Alias: imp.ID, Path: imp.Path})
+	}
+	slices.SortFunc(document.Imports, func(a, b ExportImport) int {
+		return strings.Compare(a.Alias, b.Alias)
+	})
+
+	for _, service := range e.container.Services {
+		document.Services = append(document.Services, e.exportService(service))
+		document.Dependencies = append(document.Dependencies, e.exportDependencies(service)...)
+	}
+	for _, container := range e.container.Containers {
+		exported := ExportContainer{
+			Name:     container.Name,
+			Type:     container.Type.Name,
+			Services: make([]ExportService, 0, len(container.Services)),
+		}
+		for _, service := range container.Services {
+			exported.Services = append(exported.Services, e.exportService(service))
+			document.Dependencies = append(document.Dependencies, e.exportDependencies(service)...)
+		}
+		document.Containers = append(document.Containers, exported)
+	}
+
+	return document
+}
+
+func (e *Exporter) exportService(service *ServiceDefinition) ExportService {
+	exported := ExportService{
+		ID:        service.FullName(),
+		Name:      service.Name,
+		Container: service.Prefix,
+		Type:      e.exportType(service.Type),
+		Flags: ExportFlags{
+			Set:      service.HasSetter,
+			Close:    service.HasCloser,
+			Required: service.IsRequired,
+			Public:   service.IsPublic,
+			Named:    service.IsNamed,
+		},
+		CloseMethod: service.CloseMethod,
+		StartMethod: service.StartMethod,
+		StopMethod:  service.StopMethod,
+		From:        service.From,
+	}
+	if service.IsPublic {
+		exported.PublicName = service.PublicTitle()
+	}
+	if service.EnvVar != "" {
+		exported.Env = &ExportEnv{Name: service.EnvVar}
+		if service.HasDefault {
+			exported.Env.Default = &service.Default
+		}
+	}
+	if service.HasValue {
+		exported.Value = &service.Value
+	}
+	if service.HasFactory() {
+		exported.Factory = e.exportFactory(service)
+	}
+
+	return exported
+}
+
+func (e *Exporter) exportType(definition TypeDefinition) ExportType {
+	exported := ExportType{
+		Expression: definition.String(),
+		Package:    e.container.PackageName(definition),
+		Name:       definition.Name,
+		Pointer:    definition.IsPointer,
+		Slice:      definition.IsSlice,
+	}
+	if definition.IsMap() {
+		key := e.exportType(*definition.Key)
+		exported.Key = &key
+	}
+
+	return exported
+}
+
+func (e *Exporter) exportFactory(service *ServiceDefinition) *ExportFactory {
+	factoryName := strings.Title(service.Prefix) + service.Title()
+	factory := &ExportFactory{
+		Package:      service.FactoryPackage,
+		Function:     e.params.Factories.Name(service),
+		ReturnsError: e.params.Factories.ReturnError(),
+	}
+	if factory.Package == "" {
+		factory.Package = e.params.packageName(FactoriesPackage)
+	}
+	if e.params.isFactoryMethod(service) {
+		factory.Receiver = "*" + e.params.Factories.Struct
+	}
+	defaultFilename := "container.go"
+	if service.Prefix != "" {
+		defaultFilename = strcase.ToSnake(service.Prefix) + ".go"
+	}
+	factory.File = e.fileLocator.GetFactoryFilePath(service, defaultFilename)
+	if definition, exists := e.container.Factories[factoryName]; exists {
+		factory.Exists = true
+		factory.ReturnsError = definition.ReturnsError
+	}
+
+	return factory
+}
+
+func (e *Exporter) exportDependencies(service *ServiceDefinition) []ExportDependency {
+	if service.Source != nil {
+		return []ExportDependency{{From: service.FullName(), To: service.Source.FullName(), Kind: "binding"}}
+	}
+	if !service.HasFactory() {
+		return nil
+	}
+	factory, exists := e.container.Factories[strings.Title(service.Prefix)+service.Title()]
+	if !exists {
+		return nil
+	}
+
+	names := make([]string, 0, len(factory.Params)+len(factory.Calls))
+	for _, param := range factory.Params {
+		if param.Service != nil && !slices.Contains(names, param.Service.FullName()) {
+			names = append(names, param.Service.FullName())
+		}
+	}
+	for _, call := range factory.Calls {
+		if e.isService(call) && !slices.Contains(names, call) {
+			names = append(names, call)
+		}
+	}
+
+	dependencies := make([]ExportDependency, 0, len(names))
+	for _, name := range names {
+		dependencies = append(dependencies, ExportDependency{From: service.FullName(), To: name, Kind: "factory"})
+	}
+
+	return dependencies
+}
+
+// isService filters calls of the lookup container by names of services, calls of container getters
+// and other methods are skipped.
+func (e *Exporter) isService(name string) bool {
+	for _, service := range e.container.Services {
+		if service.FullName() == name {
+			return true
+		}
+	}
+	for _, container := range e.container.Containers {
+		for _, service := range container.Services {
+			if service.FullName() == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -1,12 +1,12 @@
 package di
 
 import (
-	"bytes"
-	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/dave/jennifer/jen"
 	"github.com/iancoleman/strcase"
+	"github.com/muonsoft/errors"
 	"github.com/spf13/afero"
 )
 
@@ -56,73 +56,78 @@ func (g *FactoriesGenerator) Generate() ([]*File, error) {
 }
 
 func (g *FactoriesGenerator) generateNewFile(filename string, services []*ServiceDefinition) (*File, error) {
-	file := NewFileBuilder(filename, "factories")
+	packageName := g.params.Layout.Factories.Name
+	if len(services) > 0 && services[0].FactoryPackage != "" {
+		packageName = "factories"
+	}
+	file := NewFileBuilder(filename, packageName)
 	file.AddImportAliases(g.container.Imports)
+	if g.isStructFile(filename) {
+		file.Add(jen.Line(), g.generateStruct())
+	}
 
 	for _, service := range services {
-		returnCode := make([]jen.Code, 0, 2)
-		returnCode = append(returnCode, jen.Do(g.container.Type(service.Type)))
-		if g.params.Factories.ReturnError() {
-			returnCode = append(returnCode, jen.Error())
-		}
-
-		file.Add(
-			jen.Line(),
-			jen.Func().Id("Create"+strings.Title(service.Prefix)+service.Title()).
-				Params(
-					jen.Id("ctx").Qual("context", "Context"),
-					jen.Id("c").Qual(g.params.packageName(LookupPackage), "Container"),
-				).
-				Params(returnCode...).
-				Block(
-					jen.Panic(jen.Lit("not implemented")),
-				),
-		)
+		file.Add(jen.Line(), g.generateStub(service))
 	}
 
 	return file.GetFile()
 }
 
+// generateAppendFile merges missing stubs into the existing file, imports required by stubs
+// are added into the file.
 func (g *FactoriesGenerator) generateAppendFile(filename string, services []*ServiceDefinition) (*File, error) {
-	var content bytes.Buffer
+	source, err := afero.ReadFile(g.fs, filename)
+	if err != nil {
+		return nil, errors.Errorf("read factories file %s: %w", filename, err)
+	}
+	packageName, err := parsePackageName(source)
+	if err != nil {
+		return nil, errors.Errorf("parse factories file %s: %w", filename, err)
+	}
 
+	file := NewFileBuilder(filename, packageName)
+	file.AddImportAliases(g.container.Imports)
+	isEmpty := true
+	if g.isStructFile(filename) {
+		file.Add(jen.Line(), g.generateStruct())
+		isEmpty = false
+	}
 	for _, service := range services {
 		factoryName := strings.Title(service.Prefix) + service.Title()
 		if _, exists := g.container.Factories[factoryName]; exists {
 			continue
 		}
+		file.Add(jen.Line(), g.generateStub(service))
+		isEmpty = false
+	}
+	if isEmpty {
+		return &File{Name: filename, Append: true}, nil
+	}
 
-		returnCode := make([]jen.Code, 0, 2)
-		returnCode = append(returnCode, jen.Do(g.container.Type(service.Type)))
-		if g.params.Factories.ReturnError() {
-			returnCode = append(returnCode, jen.Error())
-		}
-
-		content.WriteString("\n")
-		content.WriteString(fmt.Sprintf("%#v",
-			jen.Func().Id("Create"+factoryName).
-				Params(
-					jen.Id("ctx").Qual("context", "Context"),
-					jen.Id("c").Qual(g.params.packageName(LookupPackage), "Container"),
-				).
-				Params(returnCode...).
-				Block(jen.Panic(jen.Lit("not implemented"))),
-		))
-		content.WriteString("\n")
+	stubs, err := file.GetFile()
+	if err != nil {
+		return nil, err
+	}
+	content, err := mergeDeclarations(source, stubs.Content)
+	if err != nil {
+		return nil, errors.Errorf("merge stubs into %s: %w", filename, err)
 	}
 
 	return &File{
 		Name:    filename,
-		Content: content.Bytes(),
+		Content: content,
 		Append:  true,
 	}, nil
 }
 
 func (g *FactoriesGenerator) getServicesByFiles() map[string][]*ServiceDefinition {
 	servicesByFiles := make(map[string][]*ServiceDefinition)
+	if g.params.Factories.Struct != "" && !slices.Contains(g.container.FactoryTypes, g.params.Factories.Struct) {
+		servicesByFiles[g.structFilename()] = nil
+	}
 
 	for _, service := range g.container.Services {
-		if service.IsRequired {
+		if !service.HasFactory() {
 			continue
 		}
 
@@ -134,7 +139,7 @@ func (g *FactoriesGenerator) getServicesByFiles() map[string][]*ServiceDefinitio
 		defaultFilename := strcase.ToSnake(container.Name) + ".go"
 
 		for _, service := range container.Services {
-			if service.IsRequired {
+			if !service.HasFactory() {
 				continue
 			}
 
@@ -145,3 +150,49 @@ func (g *FactoriesGenerator) getServicesByFiles() map[string][]*ServiceDefinitio
 
 	return servicesByFiles
 }
+
+// generateStub generates the factory function with the signature defined by the factories parameters.
+func (g *FactoriesGenerator) generateStub(service *ServiceDefinition) *jen.Statement {
+	containerPath, containerName := g.params.containerType()
+	params := make([]jen.Code, 0, 2)
+	if !g.params.Factories.SkipContext {
+		params = append(params, jen.Id("ctx").Qual("context", "Context"))
+	}
+	params = append(params, jen.Id("c").Qual(containerPath, containerName))
+
+	returnCode := make([]jen.Code, 0, 2)
+	returnCode = append(returnCode, jen.Do(g.container.Type(service.Type)))
+	if g.params.Factories.ReturnError() {
+		returnCode = append(returnCode, jen.Error())
+	}
+
+	stub := jen.Func()
+	if g.params.isFactoryMethod(service) {
+		stub = stub.Params(jen.Id("f").Op("*").Id(g.params.Factories.Struct))
+	}
+
+	return stub.Id(g.params.Factories.Name(service)).
+		Params(params...).
+		Params(returnCode...).
+		Block(jen.Panic(jen.Lit("not implemented")))
+}
+
+// generateStruct generates the declaration of the factories struct.
+func (g *FactoriesGenerator) generateStruct() *jen.Statement {
+	name := g.params.Factories.Struct
+
+	return jen.Commentf("%s creates services of the container, it may hold the state shared by factories.", name).
+		Line().
+		Type().Id(name).Struct()
+}
+
+// isStructFile reports whether the declaration of the factories struct must be added into the file.
+func (g *FactoriesGenerator) isStructFile(filename string) bool {
+	return g.params.Factories.Struct != "" &&
+		!slices.Contains(g.container.FactoryTypes, g.params.Factories.Struct) &&
+		filename == g.structFilename()
+}
+
+func (g *FactoriesGenerator) structFilename() string {
+	return g.fileLocator.GetPackageFilePath(FactoriesPackage, "container.go")
+}
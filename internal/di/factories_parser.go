@@ -2,14 +2,60 @@ package di
 
 import (
 	"go/ast"
+	"go/token"
 	iofs "io/fs"
+	"slices"
 	"strings"
 
 	"github.com/muonsoft/errors"
 	"github.com/spf13/afero"
 )
 
-func parseFactoriesFromDirs(fs afero.Fs, logger Logger, dirs ...string) (*FactoryDefinitions, error) {
+// factoryConvention defines how factories are recognized by the parser.
+type factoryConvention struct {
+	prefix string
+	// containerTypes are import paths and names of types recognized as the container parameter.
+	// Any type named "Container" is recognized if the list is empty.
+	containerTypes [][2]string
+	// receiver is the name of the factories struct. When it is set, only methods of the struct
+	// are recognized as factories.
+	receiver string
+}
+
+func newFactoryConvention(params GenerationParameters) factoryConvention {
+	containerPath, containerName := params.containerType()
+
+	return factoryConvention{
+		prefix: params.Factories.Prefix,
+		containerTypes: [][2]string{
+			{containerPath, containerName},
+			{params.packageName(LookupPackage), "Container"},
+		},
+	}
+}
+
+func (c factoryConvention) paramKind(expr ast.Expr, imports map[string]*ImportDefinition) FactoryParamKind {
+	path, name := "", ""
+	switch t := expr.(type) {
+	case *ast.Ident:
+		name = t.Name
+	case *ast.SelectorExpr:
+		if id, ok := t.X.(*ast.Ident); ok && imports[id.Name] != nil {
+			path, name = imports[id.Name].Path, t.Sel.Name
+		}
+	}
+
+	if path == "context" && name == "Context" {
+		return ContextParam
+	}
+	if len(c.containerTypes) == 0 && name == "Container" || slices.Contains(c.containerTypes, [2]string{path, name}) {
+		return ContainerParam
+	}
+
+	return DependencyParam
+}
+
+func parseFactoriesFromDirs(fs afero.Fs, logger Logger, convention factoryConvention, dirs ...string) (*FactoryDefinitions, error) {
 	definitions := NewFactoryDefinitions()
 
 	for _, dir := range dirs {
@@ -31,7 +77,7 @@ func parseFactoriesFromDirs(fs afero.Fs, logger Logger, dirs ...string) (*Factor
 			if err != nil {
 				return err
 			}
-			df, err := parseFactoriesAST(file)
+			df, err := parseFactoriesAST(file, convention)
 			if err != nil {
 				return err
 			}
@@ -55,33 +101,189 @@ func ParseFactoriesFromSource(source string) (*FactoryDefinitions, error) {
 		return nil, err
 	}
 
-	return parseFactoriesAST(file)
+	return parseFactoriesAST(file, factoryConvention{prefix: "Create"})
 }
 
-func parseFactoriesAST(file *ast.File) (*FactoryDefinitions, error) {
+func parseFactoriesAST(file *ast.File, convention factoryConvention) (*FactoryDefinitions, error) {
 	imports, err := parseImports(file)
 	if err != nil {
 		return nil, errors.Errorf("parse imports: %w", err)
 	}
 
 	factories := make(map[string]*FactoryDefinition, len(file.Scope.Objects))
+	types := make([]string, 0)
 
-	for name, object := range file.Scope.Objects {
-		if funcDecl, ok := object.Decl.(*ast.FuncDecl); ok && object.Kind == ast.Fun && strings.HasPrefix(name, "Create") {
-			f, err := parseFuncDeclaration(funcDecl)
-			if err != nil {
-				return nil, errors.Errorf("parse func declaration: %w", err)
-			}
-			factoryName := strings.TrimPrefix(name, "Create")
-			factories[factoryName] = &FactoryDefinition{
-				Name:         factoryName,
-				ReturnsError: f.ReturnsErr,
+	for _, decl := range file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.TYPE {
+			for _, spec := range genDecl.Specs {
+				types = append(types, spec.(*ast.TypeSpec).Name.Name)
 			}
 		}
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || !convention.isFactory(funcDecl) {
+			continue
+		}
+		name := funcDecl.Name.Name
+		f, err := parseFuncDeclaration(funcDecl)
+		if err != nil {
+			return nil, errors.Errorf("parse func declaration: %w", err)
+		}
+		params, err := parseFactoryParams(funcDecl, convention, imports)
+		if err != nil {
+			return nil, errors.Errorf("parse factory %s: %w", name, err)
+		}
+		factoryName := strings.TrimPrefix(name, convention.prefix)
+		factories[factoryName] = &FactoryDefinition{
+			Name:         factoryName,
+			ReturnsError: f.ReturnsErr,
+			Calls:        parseContainerCalls(funcDecl, params),
+			Params:       params,
+		}
 	}
 
 	return &FactoryDefinitions{
 		Imports:   imports,
 		Factories: factories,
+		Types:     types,
 	}, nil
 }
+
+// isFactory reports whether the function is the factory: the function with the prefix
+// or the method of the factories struct with the prefix.
+func (c factoryConvention) isFactory(decl *ast.FuncDecl) bool {
+	if !strings.HasPrefix(decl.Name.Name, c.prefix) {
+		return false
+	}
+	if c.receiver == "" {
+		return decl.Recv == nil
+	}
+	if decl.Recv == nil || len(decl.Recv.List) != 1 {
+		return false
+	}
+	receiver := decl.Recv.List[0].Type
+	if star, ok := receiver.(*ast.StarExpr); ok {
+		receiver = star.X
+	}
+	id, ok := receiver.(*ast.Ident)
+
+	return ok && id.Name == c.receiver
+}
+
+// parseFactoryParams classifies parameters of the factory by their types. Parameters of dependencies
+// are resolved by names, so they must be named.
+func parseFactoryParams(decl *ast.FuncDecl, convention factoryConvention, imports map[string]*ImportDefinition) ([]FactoryParam, error) {
+	params := make([]FactoryParam, 0, len(decl.Type.Params.List))
+
+	for _, field := range decl.Type.Params.List {
+		kind := convention.paramKind(field.Type, imports)
+		if len(field.Names) == 0 {
+			if kind == DependencyParam {
+				return nil, errors.Errorf("%w: dependency parameter must be named", ErrInvalidDefinition)
+			}
+			params = append(params, FactoryParam{Kind: kind})
+		}
+		for _, name := range field.Names {
+			params = append(params, FactoryParam{Name: name.Name, Kind: kind})
+		}
+	}
+
+	return params, nil
+}
+
+// parseContainerCalls finds calls of getters on the lookup container passed to the factory.
+func parseContainerCalls(decl *ast.FuncDecl, params []FactoryParam) []string {
+	i := slices.IndexFunc(params, func(param FactoryParam) bool {
+		return param.Kind == ContainerParam && param.Name != "" && param.Name != "_"
+	})
+	if decl.Body == nil || i < 0 {
+		return nil
+	}
+	container := params[i].Name
+
+	calls := make([]string, 0)
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		getter, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		name := ""
+		switch x := getter.X.(type) {
+		case *ast.Ident:
+			if x.Name == container {
+				name = getter.Sel.Name
+			}
+		case *ast.CallExpr:
+			if attached, ok := x.Fun.(*ast.SelectorExpr); ok {
+				if id, ok := attached.X.(*ast.Ident); ok && id.Name == container {
+					name = attached.Sel.Name + "." + getter.Sel.Name
+				}
+			}
+		}
+		if name != "" && !slices.Contains(calls, name) {
+			calls = append(calls, name)
+		}
+
+		return true
+	})
+
+	return calls
+}
+
+// resolveFactoryDependencies resolves services of dependency parameters of factories by names of parameters.
+// The service is searched in the container of the factory and then in the root container, the name of the parameter
+// may be also qualified by the name of attached container, for example "repositoriesEntityRepository".
+func resolveFactoryDependencies(container *RootContainerDefinition) error {
+	services := slices.Clone(container.Services)
+	for _, attachedContainer := range container.Containers {
+		services = append(services, attachedContainer.Services...)
+	}
+
+	for _, service := range services {
+		factory := container.Factories[strings.Title(service.Prefix)+service.Title()]
+		if !service.HasFactory() || factory == nil {
+			continue
+		}
+		for i, param := range factory.Params {
+			if param.Kind != DependencyParam {
+				continue
+			}
+			dependency := findDependency(services, service.Prefix, param.Name)
+			if dependency == nil {
+				return errors.Errorf(
+					"%w: factory of service %s: service of parameter %q not found",
+					ErrInvalidDefinition, service.FullName(), param.Name,
+				)
+			}
+			if dependency == service {
+				return errors.Errorf(
+					"%w: factory of service %s: parameter %q refers to the service itself",
+					ErrInvalidDefinition, service.FullName(), param.Name,
+				)
+			}
+			factory.Params[i].Service = dependency
+		}
+	}
+
+	return nil
+}
+
+func findDependency(services []*ServiceDefinition, prefix, name string) *ServiceDefinition {
+	for _, p := range []string{prefix, ""} {
+		for _, service := range services {
+			if service.Prefix == p && strings.EqualFold(service.Name, name) {
+				return service
+			}
+		}
+	}
+	for _, service := range services {
+		if service.Prefix != "" && strings.EqualFold(service.Prefix+service.Name, name) {
+			return service
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,397 @@
+package di
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	iofs "io/fs"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/muonsoft/errors"
+	"github.com/spf13/afero"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// factoryChangedMarker is added above factories with rewritten signatures,
+// bodies of such factories may need to be updated manually.
+const factoryChangedMarker = "// TODO(digen): type changed"
+
+// FactorySynchronizer rewrites signatures of existing factories mismatching the service definitions:
+// the return type, unsupported results and types of dependency parameters. Bodies of factories
+// are left untouched.
+type FactorySynchronizer struct {
+	fs        afero.Fs
+	logger    Logger
+	container *RootContainerDefinition
+	params    GenerationParameters
+	services  map[string]*ServiceDefinition
+}
+
+func NewFactorySynchronizer(
+	fs afero.Fs,
+	logger Logger,
+	container *RootContainerDefinition,
+	params GenerationParameters,
+) *FactorySynchronizer {
+	services := make(map[string]*ServiceDefinition)
+	add := func(service *ServiceDefinition) {
+		if service.HasFactory() {
+			services[strings.Title(service.Prefix)+service.Title()] = service
+		}
+	}
+	for _, service := range container.Services {
+		add(service)
+	}
+	for _, attachedContainer := range container.Containers {
+		for _, service := range attachedContainer.Services {
+			add(service)
+		}
+	}
+
+	return &FactorySynchronizer{
+		fs:        fs,
+		logger:    logger,
+		container: container,
+		params:    params,
+		services:  services,
+	}
+}
+
+// Synchronize rewrites factories found in the dirs and returns the count of rewritten factories.
+func (s *FactorySynchronizer) Synchronize(sources []factorySource) (int, error) {
+	count := 0
+
+	for _, source := range sources {
+		if !isFileExist(s.fs, source.dir) {
+			continue
+		}
+		err := afero.Walk(s.fs, source.dir, func(path string, d iofs.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !strings.HasSuffix(path, ".go") {
+				return nil
+			}
+			n, err := s.synchronizeFile(path, source.convention)
+			if err != nil {
+				return errors.Errorf("synchronize factories of %s: %w", path, err)
+			}
+			count += n
+
+			return nil
+		})
+		if err != nil {
+			return count, errors.Errorf("walk dir %q: %w", source.dir, err)
+		}
+	}
+
+	return count, nil
+}
+
+func (s *FactorySynchronizer) synchronizeFile(filename string, convention factoryConvention) (int, error) {
+	source, err := afero.ReadFile(s.fs, filename)
+	if err != nil {
+		return 0, errors.Errorf("read file: %w", err)
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, source, parser.ParseComments)
+	if err != nil {
+		return 0, errors.Errorf("%w: %w", ErrParsing, err)
+	}
+	imports, err := newFileImports(fset, file)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := make([]int, 0)
+	for i, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || !convention.isFactory(funcDecl) {
+			continue
+		}
+		name := strings.TrimPrefix(funcDecl.Name.Name, convention.prefix)
+		service := s.services[name]
+		if service == nil {
+			continue
+		}
+		changes := s.synchronizeSignature(funcDecl, service, s.container.Factories[name], imports)
+		for _, change := range changes {
+			s.logger.Info("factory", funcDecl.Name.Name, "in", filename+":", change)
+		}
+		if len(changes) > 0 {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return 0, nil
+	}
+	imports.removeUnused()
+
+	var buffer bytes.Buffer
+	if err := format.Node(&buffer, fset, file); err != nil {
+		return 0, errors.Errorf("print source: %w", err)
+	}
+	content, err := addChangedMarkers(buffer.Bytes(), changed)
+	if err != nil {
+		return 0, err
+	}
+	if err := afero.WriteFile(s.fs, filename, content, 0644); err != nil {
+		return 0, errors.Errorf("write file: %w", err)
+	}
+
+	return len(changed), nil
+}
+
+// synchronizeSignature rewrites the signature of the factory and returns descriptions of changes.
+func (s *FactorySynchronizer) synchronizeSignature(
+	decl *ast.FuncDecl,
+	service *ServiceDefinition,
+	factory *FactoryDefinition,
+	imports *fileImports,
+) []string {
+	changes := make([]string, 0)
+
+	if decl.Type.Results == nil {
+		decl.Type.Results = &ast.FieldList{}
+	}
+	results := decl.Type.Results
+	want := s.typeExpr(service.Type, imports)
+	if len(results.List) == 0 {
+		results.List = append(results.List, &ast.Field{Type: want})
+		changes = append(changes, fmt.Sprintf("return type added: %s", types.ExprString(want)))
+		if s.params.Factories.ReturnError() {
+			results.List = append(results.List, &ast.Field{Type: ast.NewIdent("error")})
+			changes = append(changes, "error result added")
+		}
+	} else if got := results.List[0].Type; types.ExprString(got) != types.ExprString(want) {
+		imports.replaced(got)
+		results.List[0].Type = want
+		changes = append(changes, fmt.Sprintf("return type changed: %s -> %s", types.ExprString(got), types.ExprString(want)))
+	}
+
+	// the container calls factories with or without the error result, so only results
+	// it cannot handle are removed, the error result is kept as it is
+	if len(results.List) > 1 {
+		last := results.List[len(results.List)-1]
+		kept, unsupported := results.List[:1:1], results.List[1:]
+		if types.ExprString(last.Type) == "error" {
+			kept, unsupported = append(kept, last), unsupported[:len(unsupported)-1]
+		}
+		if len(unsupported) > 0 {
+			for _, field := range unsupported {
+				imports.replaced(field.Type)
+			}
+			results.List = kept
+			changes = append(changes, "unsupported results removed")
+		}
+	}
+
+	if factory != nil {
+		changes = append(changes, s.synchronizeParams(decl, factory, imports)...)
+	}
+
+	return changes
+}
+
+// synchronizeParams rewrites types of dependency parameters to types of resolved services.
+func (s *FactorySynchronizer) synchronizeParams(decl *ast.FuncDecl, factory *FactoryDefinition, imports *fileImports) []string {
+	changes := make([]string, 0)
+	fields := make([]*ast.Field, 0, len(decl.Type.Params.List))
+
+	i := 0
+	for _, field := range decl.Type.Params.List {
+		count := max(len(field.Names), 1)
+		if i+count > len(factory.Params) {
+			return changes
+		}
+		params := factory.Params[i : i+count]
+		i += count
+
+		wants := make([]ast.Expr, len(params))
+		for j, param := range params {
+			if param.Kind == DependencyParam && param.Service != nil {
+				wants[j] = s.typeExpr(param.Service.Type, imports)
+			}
+		}
+		if !slices.ContainsFunc(wants, func(want ast.Expr) bool {
+			return want != nil && types.ExprString(want) != types.ExprString(field.Type)
+		}) {
+			fields = append(fields, field)
+			continue
+		}
+
+		// names of the field may refer to services of different types, so the field is split
+		imports.replaced(field.Type)
+		for j, param := range params {
+			typ := field.Type
+			if wants[j] != nil {
+				typ = wants[j]
+				if types.ExprString(typ) != types.ExprString(field.Type) {
+					changes = append(changes, fmt.Sprintf(
+						"parameter %s changed: %s -> %s", param.Name, types.ExprString(field.Type), types.ExprString(typ),
+					))
+				}
+			}
+			fields = append(fields, &ast.Field{Names: []*ast.Ident{ast.NewIdent(param.Name)}, Type: typ})
+		}
+	}
+	decl.Type.Params.List = fields
+
+	return changes
+}
+
+// typeExpr returns the expression of the type with aliases of the file.
+func (s *FactorySynchronizer) typeExpr(definition TypeDefinition, imports *fileImports) ast.Expr {
+	var expr ast.Expr = ast.NewIdent(definition.Name)
+	if imp, ok := s.container.Imports[definition.Package]; ok {
+		expr = &ast.SelectorExpr{X: ast.NewIdent(imports.name(imp)), Sel: ast.NewIdent(definition.Name)}
+	}
+
+	if definition.IsPointer {
+		expr = &ast.StarExpr{X: expr}
+	} else if definition.IsSlice {
+		expr = &ast.ArrayType{Elt: expr}
+	}
+	if definition.IsMap() {
+		expr = &ast.MapType{Key: s.typeExpr(*definition.Key, imports), Value: expr}
+	}
+
+	return expr
+}
+
+// fileImports tracks imports of the file while types of the signatures are rewritten.
+type fileImports struct {
+	fset  *token.FileSet
+	file  *ast.File
+	names map[string]string
+	taken map[string]bool
+	// candidates are paths of packages which may become unused after rewriting
+	candidates map[string]string
+}
+
+func newFileImports(fset *token.FileSet, file *ast.File) (*fileImports, error) {
+	names, taken, err := importedNames(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileImports{
+		fset:       fset,
+		file:       file,
+		names:      names,
+		taken:      taken,
+		candidates: make(map[string]string),
+	}, nil
+}
+
+// name returns the local name of the imported package, the package is imported if it is missing.
+func (i *fileImports) name(imp *ImportDefinition) string {
+	if name, exists := i.names[imp.Path]; exists {
+		return name
+	}
+
+	name := imp.ID
+	for n := 2; i.taken[name]; n++ {
+		name = imp.ID + strconv.Itoa(n)
+	}
+	alias := name
+	if imp.Name == "" && name == imp.ID {
+		alias = ""
+	}
+	astutil.AddNamedImport(i.fset, i.file, alias, imp.Path)
+	i.names[imp.Path] = name
+	i.taken[name] = true
+
+	return name
+}
+
+// replaced remembers packages used by the replaced type expression.
+func (i *fileImports) replaced(expr ast.Expr) {
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if id, ok := selector.X.(*ast.Ident); ok {
+				for path, name := range i.names {
+					if name == id.Name {
+						i.candidates[path] = name
+					}
+				}
+			}
+		}
+
+		return true
+	})
+}
+
+// removeUnused removes imports of replaced types which are not used anymore.
+func (i *fileImports) removeUnused() {
+	for path, name := range i.candidates {
+		if isPackageUsed(i.file, name) {
+			continue
+		}
+		for _, spec := range i.file.Imports {
+			imp, err := parseImportDefinition(spec)
+			if err == nil && imp.Path == path && imp.ID == name {
+				astutil.DeleteNamedImport(i.fset, i.file, imp.Name, path)
+			}
+		}
+		delete(i.names, path)
+	}
+}
+
+func isPackageUsed(file *ast.File, name string) bool {
+	used := false
+	for _, decl := range file.Decls {
+		ast.Inspect(decl, func(node ast.Node) bool {
+			if selector, ok := node.(*ast.SelectorExpr); ok {
+				if id, ok := selector.X.(*ast.Ident); ok && id.Name == name && id.Obj == nil {
+					used = true
+				}
+			}
+
+			return !used
+		})
+	}
+
+	return used
+}
+
+// addChangedMarkers adds the marker above declarations by their indexes. Positions of the rewritten
+// file are changed, so the printed source is parsed again.
+func addChangedMarkers(source []byte, indexes []int) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", source, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Errorf("%w: rewritten source: %w", ErrParsing, err)
+	}
+
+	offsets := make([]int, 0, len(indexes))
+	for _, i := range indexes {
+		position := fset.Position(file.Decls[i].(*ast.FuncDecl).Type.Func)
+		offset := position.Offset - position.Column + 1
+		if !bytes.HasSuffix(bytes.TrimRight(source[:offset], "\n"), []byte(factoryChangedMarker)) {
+			offsets = append(offsets, offset)
+		}
+	}
+
+	var buffer bytes.Buffer
+	previous := 0
+	for _, offset := range offsets {
+		buffer.Write(source[previous:offset])
+		buffer.WriteString(factoryChangedMarker + "\n")
+		previous = offset
+	}
+	buffer.Write(source[previous:])
+
+	content, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return nil, errors.Errorf("%w: rewritten source: %w", ErrParsing, err)
+	}
+
+	return content, nil
+}
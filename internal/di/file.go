@@ -18,20 +18,16 @@ const (
 	DefinitionsPackage
 	FactoriesPackage
 	LookupPackage
+	TestingPackage
+	LookupTestPackage
 	lastPackage
 )
 
-var packageDirs = [lastPackage]string{
-	InternalPackage:    "internal",
-	DefinitionsPackage: "internal/definitions",
-	FactoriesPackage:   "internal/factories",
-	LookupPackage:      "lookup",
-}
-
 type File struct {
 	Name    string
 	Content []byte
-	Append  bool
+	// Append is true if the content is merged into the existing file.
+	Append bool
 }
 
 func (f *File) IsEmpty() bool {
@@ -52,9 +48,12 @@ func NewFileBuilder(filename, packageName string) *FileBuilder {
 	}
 }
 
-func (b *FileBuilder) AddHeading(version string) {
+func (b *FileBuilder) AddHeading(params GenerationParameters) {
+	if header := params.Templates.header(); header != "" {
+		b.file.HeaderComment(header)
+	}
 	b.file.PackageComment("Code generated by DIGEN; DO NOT EDIT.")
-	b.file.PackageComment("This file was generated by Dependency Injection Container Generator " + version + ".")
+	b.file.PackageComment("This file was generated by Dependency Injection Container Generator " + params.Version + ".")
 	b.file.PackageComment("See docs at https://github.com/strider2038/digen")
 }
 
@@ -25,11 +25,17 @@ func NewFileGenerator(
 func (g *FileGenerator) GenerateFiles() ([]*File, error) {
 	files := make([]*File, 0)
 
-	generators := [...]func() (*File, error){
+	generators := []func() (*File, error){
 		NewInternalContainerGenerator(g.fileLocator, g.container, g.params).Generate,
 		NewLookupContainerGenerator(g.fileLocator, g.container, g.params).Generate,
 		NewPublicContainerGenerator(g.fileLocator, g.container, g.params).Generate,
 	}
+	if g.params.LookupFakes {
+		generators = append(generators, NewLookupContainerGenerator(g.fileLocator, g.container, g.params).GenerateFakes)
+	}
+	if g.params.TestSupport {
+		generators = append(generators, NewTestContainerGenerator(g.fileLocator, g.container, g.params).Generate)
+	}
 
 	for _, generate := range generators {
 		file, err := generate()
@@ -7,6 +7,7 @@ import (
 type FileLocator struct {
 	ContainerDir string
 	ModulePath   string
+	Layout       PackageLayout
 }
 
 func (l *FileLocator) GetFactoryFilePath(service *ServiceDefinition, defaultFilename string) string {
@@ -26,12 +27,13 @@ func (l *FileLocator) GetContainerFilePath(filename string) string {
 
 func (l *FileLocator) GetPackageFilePath(packageType PackageType, filename string) string {
 	var s strings.Builder
-	s.Grow(len(l.ContainerDir) + len(packageDirs[packageType]) + len(filename) + 2)
+	dir := l.Layout.Package(packageType).Dir
+	s.Grow(len(l.ContainerDir) + len(dir) + len(filename) + 2)
 
 	s.WriteString(l.ContainerDir)
 	s.WriteString("/")
-	if packageDirs[packageType] != "" {
-		s.WriteString(packageDirs[packageType])
+	if dir != "" {
+		s.WriteString(dir)
 		s.WriteString("/")
 	}
 	s.WriteString(filename)
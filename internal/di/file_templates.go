@@ -0,0 +1,229 @@
+package di
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/muonsoft/errors"
+	"github.com/spf13/afero"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// TemplatesParameters customizes the generated files without forking the generator.
+type TemplatesParameters struct {
+	// Header is the text added as a comment on top of the generated files, for example a license.
+	Header string
+	// BuildTags is the build constraint expression added as "//go:build" line into the generated files.
+	BuildTags string
+	// Overrides are paths to Go templates replacing the generated files. Keys are paths of the generated
+	// files relative to the container dir, for example "internal/container.go". The generated content
+	// is passed into the template as .Content, so the template may change it or render a new one.
+	Overrides map[string]string
+	// Extras are paths to Go templates of the code appended to the generated files. Keys are the same
+	// as for overrides. Imports at the beginning of the code are merged with imports of the file.
+	Extras map[string]string
+}
+
+// header returns the comment placed above the heading of the generated files.
+func (p TemplatesParameters) header() string {
+	lines := make([]string, 0)
+	if p.BuildTags != "" {
+		lines = append(lines, "//go:build "+p.BuildTags)
+	}
+	if p.Header != "" {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		for _, line := range strings.Split(strings.TrimRight(p.Header, "\n"), "\n") {
+			lines = append(lines, strings.TrimRight("// "+line, " "))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// heading returns the heading of the generated files rendered from skeletons.
+func (params GenerationParameters) heading() string {
+	heading := fmt.Sprintf(headingTemplate, params.Version)
+	if header := params.Templates.header(); header != "" {
+		return header + "\n\n" + heading
+	}
+
+	return heading
+}
+
+// TemplateData is passed into the templates of the generated files.
+type TemplateData struct {
+	// Container is the parsed model of the container.
+	Container *RootContainerDefinition
+	Params    GenerationParameters
+	// Filename is the path of the generated file relative to the container dir.
+	Filename string
+	// Content is the generated content of the file.
+	Content string
+}
+
+var templateFunctions = template.FuncMap{
+	"replace": strings.ReplaceAll,
+	"title":   strings.Title,
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"join":    strings.Join,
+}
+
+type fileTemplates struct {
+	overrides map[string]*template.Template
+	extras    map[string]*template.Template
+	applied   map[string]bool
+}
+
+func loadFileTemplates(fs afero.Fs, params TemplatesParameters) (*fileTemplates, error) {
+	templates := &fileTemplates{applied: make(map[string]bool)}
+
+	var err error
+	templates.overrides, err = parseFileTemplates(fs, params.Overrides)
+	if err != nil {
+		return nil, errors.Errorf("override: %w", err)
+	}
+	templates.extras, err = parseFileTemplates(fs, params.Extras)
+	if err != nil {
+		return nil, errors.Errorf("extra: %w", err)
+	}
+
+	return templates, nil
+}
+
+func parseFileTemplates(fs afero.Fs, paths map[string]string) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template, len(paths))
+
+	for filename, templatePath := range paths {
+		text, err := afero.ReadFile(fs, templatePath)
+		if err != nil {
+			return nil, errors.Errorf("read template of %s: %w", filename, err)
+		}
+		tmpl, err := template.New(templatePath).Funcs(templateFunctions).Parse(string(text))
+		if err != nil {
+			return nil, errors.Errorf("%w: template of %s: %w", ErrParsing, filename, err)
+		}
+		templates[filename] = tmpl
+	}
+
+	return templates, nil
+}
+
+// Apply renders the override and the extra code of the file. Go files are formatted after rendering.
+func (t *fileTemplates) Apply(file *File, data TemplateData) error {
+	override, extra := t.overrides[data.Filename], t.extras[data.Filename]
+	if override == nil && extra == nil {
+		return nil
+	}
+	t.applied[data.Filename] = true
+	data.Content = string(file.Content)
+
+	content := file.Content
+	if override != nil {
+		rendered, err := executeTemplate(override, data)
+		if err != nil {
+			return errors.Errorf("render override of %s: %w", data.Filename, err)
+		}
+		content = rendered
+		data.Content = string(rendered)
+	}
+
+	isGo := path.Ext(data.Filename) == ".go"
+	if extra != nil {
+		code, err := executeTemplate(extra, data)
+		if err != nil {
+			return errors.Errorf("render extra of %s: %w", data.Filename, err)
+		}
+		if isGo {
+			content, err = mergeCode(content, code)
+			if err != nil {
+				return errors.Errorf("merge extra of %s: %w", data.Filename, err)
+			}
+		} else {
+			content = slices.Concat(content, code)
+		}
+	}
+
+	if isGo {
+		formatted, err := format.Source(content)
+		if err != nil {
+			return errors.Errorf("%w: rendered %s: %w", ErrParsing, data.Filename, err)
+		}
+		content = formatted
+	}
+	file.Content = content
+
+	return nil
+}
+
+// Unknown returns sorted keys of templates not matching any generated file.
+func (t *fileTemplates) Unknown() []string {
+	unknown := make([]string, 0)
+	for _, templates := range []map[string]*template.Template{t.overrides, t.extras} {
+		for filename := range templates {
+			if !t.applied[filename] && !slices.Contains(unknown, filename) {
+				unknown = append(unknown, filename)
+			}
+		}
+	}
+	slices.Sort(unknown)
+
+	return unknown
+}
+
+func executeTemplate(tmpl *template.Template, data TemplateData) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// mergeCode appends the code to the Go source, imports at the beginning of the code
+// are added into imports of the source.
+func mergeCode(source, code []byte) ([]byte, error) {
+	const codePackage = "package extra\n"
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", source, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Errorf("%w: %w", ErrParsing, err)
+	}
+	codeFile, err := parser.ParseFile(fset, "", codePackage+string(code), parser.ImportsOnly)
+	if err != nil {
+		return nil, errors.Errorf("%w: %w", ErrParsing, err)
+	}
+
+	if len(codeFile.Decls) > 0 {
+		end := fset.Position(codeFile.Decls[len(codeFile.Decls)-1].End()).Offset - len(codePackage)
+		code = code[end:]
+	}
+	for _, spec := range codeFile.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := ""
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		astutil.AddNamedImport(fset, file, name, importPath)
+	}
+
+	var buffer bytes.Buffer
+	if err := format.Node(&buffer, fset, file); err != nil {
+		return nil, errors.Errorf("print source: %w", err)
+	}
+	buffer.WriteString("\n")
+	buffer.Write(code)
+
+	return buffer.Bytes(), nil
+}
@@ -1,10 +1,14 @@
 package di
 
 import (
+	"go/token"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dave/jennifer/jen"
+	"github.com/muonsoft/errors"
 )
 
 type GenerationParameters struct {
@@ -12,35 +16,182 @@ type GenerationParameters struct {
 	ErrorHandling ErrorHandling
 	Factories     FactoriesParameters
 	Version       string
+
+	// TypeCheck enables validation of service definitions against the actual Go types.
+	TypeCheck bool
+	// Concurrency sets up synchronization mode of the generated containers.
+	Concurrency ConcurrencyMode
+	// Hooks enables notification of hooks about construction of services.
+	Hooks bool
+	// Describe enables the description of services with their runtime state.
+	Describe bool
+	// TestSupport enables generation of ditest package with the container for tests.
+	TestSupport bool
+	// LookupFakes enables generation of lookuptest package with fakes of lookup containers.
+	LookupFakes bool
+	// StopTimeout bounds the stop of long-running services by Run method, 30 seconds by default.
+	StopTimeout time.Duration
+	// Templates customizes the generated files.
+	Templates TemplatesParameters
+	// Layout defines directories and names of the generated packages.
+	Layout PackageLayout
+}
+
+// ConcurrencyMode defines how the generated containers are synchronized.
+type ConcurrencyMode string
+
+const (
+	// GlobalConcurrency mode serializes all getters of public container by a single mutex.
+	GlobalConcurrency ConcurrencyMode = "global"
+	// ServiceConcurrency mode synchronizes every service separately in the internal container.
+	// Already initialized services are accessed without locking.
+	ServiceConcurrency ConcurrencyMode = "service"
+)
+
+func (mode ConcurrencyMode) IsValid() bool {
+	return mode == GlobalConcurrency || mode == ServiceConcurrency
 }
 
+const defaultStopTimeout = 30 * time.Second
+
 func (params GenerationParameters) Defaults() GenerationParameters {
 	params.ErrorHandling = params.ErrorHandling.Defaults()
+	if params.Factories.Prefix == "" {
+		params.Factories.Prefix = "Create"
+	}
+	if params.Concurrency == "" {
+		params.Concurrency = GlobalConcurrency
+	}
+	if params.StopTimeout == 0 {
+		params.StopTimeout = defaultStopTimeout
+	}
 	if params.Version == "" {
 		params.Version = "(unknown version)"
 	}
+	params.Layout = params.Layout.Defaults()
 
 	return params
 }
 
 type FactoriesParameters struct {
 	SkipError bool
+	// Prefix is the prefix of factory function names, "Create" by default.
+	Prefix string
+	// SkipContext disables the context parameter in generated stubs of factories.
+	SkipContext bool
+	// ContainerType is the qualified type of the container parameter in generated stubs,
+	// for example "example.com/project/di/contracts.Container". The type must be implemented
+	// by the internal container, by default it is the lookup container.
+	ContainerType string
+	// Struct is the name of the struct type in the factories package. When it is set, factories
+	// are methods of the struct and its instance is passed into the constructor of the container.
+	Struct string
 }
 
 func (p FactoriesParameters) ReturnError() bool {
 	return !p.SkipError
 }
 
+// Name returns the name of the factory function of the service.
+func (p FactoriesParameters) Name(service *ServiceDefinition) string {
+	return p.Prefix + strings.Title(service.Prefix) + service.Title()
+}
+
+// publicNames are declarations of the generated public package.
+var publicNames = []string{
+	"Container", "Injector", "NewContainer", "ServiceError", "Hooks",
+	"ErrServiceNotFound", "ServiceInfo", "PanicError", "Override",
+}
+
+func (p FactoriesParameters) validate() error {
+	if !token.IsExported(p.Prefix) || !token.IsIdentifier(p.Prefix) {
+		return errors.Errorf("%w: factory prefix %q, it must be an exported identifier", ErrNotSupported, p.Prefix)
+	}
+	if p.Struct != "" && (!token.IsExported(p.Struct) || !token.IsIdentifier(p.Struct)) {
+		return errors.Errorf("%w: factories struct %q, it must be an exported identifier", ErrNotSupported, p.Struct)
+	}
+	if slices.Contains(publicNames, p.Struct) {
+		return errors.Errorf("%w: factories struct %q collides with the declaration of the public package", ErrNotSupported, p.Struct)
+	}
+	if p.ContainerType != "" {
+		i := strings.LastIndex(p.ContainerType, ".")
+		if i <= 0 || !token.IsIdentifier(p.ContainerType[i+1:]) {
+			return errors.Errorf("%w: container type %q of factories, it must be qualified by the import path", ErrNotSupported, p.ContainerType)
+		}
+	}
+
+	return nil
+}
+
 type ErrorHandling struct {
 	New  ErrorOptions
 	Join ErrorOptions
 	Wrap ErrorOptions
+
+	// Policy defines how the container behaves after a service initialization failure.
+	Policy ErrorPolicy
+	// RetryBackoff is the minimal interval between initialization attempts of the failed
+	// service in the isolate policy. The last error is returned until the interval is passed.
+	RetryBackoff time.Duration
+	// Explicit enables getters of internal and lookup containers returning (T, error).
+	// Errors are returned to the caller and never accumulated by the container.
+	Explicit bool
+	// RecoverPanics enables recovering panics of factories in the internal container.
+	// Recovered panics are handled by the error policy as errors with the stack trace.
+	RecoverPanics bool
+}
+
+// ErrorPolicy defines how initialization errors affect the container.
+type ErrorPolicy string
+
+const (
+	// PoisonErrorPolicy stops initialization of all services after the first error.
+	PoisonErrorPolicy ErrorPolicy = "poison"
+	// IsolateErrorPolicy tracks errors per service. Unrelated services keep working
+	// and the failed service is initialized again on the next call.
+	IsolateErrorPolicy ErrorPolicy = "isolate"
+)
+
+func (policy ErrorPolicy) IsValid() bool {
+	return policy == PoisonErrorPolicy || policy == IsolateErrorPolicy
 }
 
 type ErrorOptions struct {
 	Package  string
 	Function string
 	Verb     string
+	// Arguments defines the argument layout of the function.
+	Arguments ArgumentsLayout
+}
+
+// ArgumentsLayout defines how arguments are passed to the error functions.
+type ArgumentsLayout string
+
+const (
+	// FormatArguments layout is used for Errorf-shaped functions: Errorf("message: %w", err)
+	// for wrapping and Errorf("format", args...) for new errors.
+	FormatArguments ArgumentsLayout = "format"
+	// MessageFirstArguments layout is used for functions like Wrap("message", err)
+	// for wrapping and New("message") for new errors.
+	MessageFirstArguments ArgumentsLayout = "messageFirst"
+	// ErrorFirstArguments layout is used for wrapping functions like Wrap(err, "message")
+	// of github.com/pkg/errors.
+	ErrorFirstArguments ArgumentsLayout = "errorFirst"
+	// ServiceNameArguments layout is used for wrapping functions like Service("ServiceName", err).
+	// The action with the service is kept by fmt.Errorf, like Service("ServiceName", fmt.Errorf("close: %w", err)),
+	// errors not bound to a service are wrapped by fmt.Errorf only.
+	ServiceNameArguments ArgumentsLayout = "serviceName"
+)
+
+func (layout ArgumentsLayout) isValidForWrap() bool {
+	return layout == FormatArguments ||
+		layout == MessageFirstArguments ||
+		layout == ErrorFirstArguments ||
+		layout == ServiceNameArguments
+}
+
+func (layout ArgumentsLayout) isValidForNew() bool {
+	return layout == FormatArguments || layout == MessageFirstArguments
 }
 
 func (w ErrorHandling) Defaults() ErrorHandling {
@@ -50,6 +201,9 @@ func (w ErrorHandling) Defaults() ErrorHandling {
 	if w.New.Function == "" {
 		w.New.Function = "Errorf"
 	}
+	if w.New.Arguments == "" {
+		w.New.Arguments = FormatArguments
+	}
 	if w.Join.Package == "" {
 		w.Join.Package = "errors"
 	}
@@ -65,11 +219,20 @@ func (w ErrorHandling) Defaults() ErrorHandling {
 	if w.Wrap.Verb == "" {
 		w.Wrap.Verb = "%w"
 	}
+	if w.Wrap.Arguments == "" {
+		w.Wrap.Arguments = FormatArguments
+	}
+	if w.Policy == "" {
+		w.Policy = PoisonErrorPolicy
+	}
 
 	return w
 }
 
 func (params GenerationParameters) rootPackageName() string {
+	if params.Layout.Public.Name != "" {
+		return params.Layout.Public.Name
+	}
 	path := strings.Split(params.RootPackage, "/")
 	if len(path) == 0 {
 		return ""
@@ -78,15 +241,128 @@ func (params GenerationParameters) rootPackageName() string {
 }
 
 func (params GenerationParameters) packageName(packageType PackageType) string {
-	return strings.Trim(strconv.Quote(params.RootPackage+"/"+packageDirs[packageType]), `"`)
+	return strings.Trim(strconv.Quote(params.RootPackage+"/"+params.Layout.Package(packageType).Dir), `"`)
+}
+
+// containerType returns the import path and the name of the container parameter in stubs of factories.
+func (params GenerationParameters) containerType() (string, string) {
+	if params.Factories.ContainerType == "" {
+		return params.packageName(LookupPackage), "Container"
+	}
+	i := strings.LastIndex(params.Factories.ContainerType, ".")
+
+	return params.Factories.ContainerType[:i], params.Factories.ContainerType[i+1:]
+}
+
+// factoriesStruct returns the pointer type of the factories struct.
+func (params GenerationParameters) factoriesStruct() *jen.Statement {
+	return jen.Op("*").Qual(params.packageName(FactoriesPackage), params.Factories.Struct)
+}
+
+// isFactoryMethod reports whether the factory of the service is the method of the factories struct.
+// Factories of outer packages are always functions.
+func (params GenerationParameters) isFactoryMethod(service *ServiceDefinition) bool {
+	return params.Factories.Struct != "" && service.FactoryPackage == ""
+}
+
+// defaultFactoryParams returns parameters of generated stubs of factories.
+func (params GenerationParameters) defaultFactoryParams() []FactoryParam {
+	factoryParams := make([]FactoryParam, 0, 2)
+	if !params.Factories.SkipContext {
+		factoryParams = append(factoryParams, FactoryParam{Name: "ctx", Kind: ContextParam})
+	}
+
+	return append(factoryParams, FactoryParam{Name: "c", Kind: ContainerParam})
 }
 
 func (params GenerationParameters) wrapError(message string, errorIdentifier jen.Code) *jen.Statement {
-	path := params.ErrorHandling.Wrap.Package
-	funcName := params.ErrorHandling.Wrap.Function
-	verb := params.ErrorHandling.Wrap.Verb
+	wrap := params.ErrorHandling.Wrap
+	function := jen.Qual(wrap.Package, wrap.Function)
+
+	switch wrap.Arguments {
+	case ServiceNameArguments:
+		return errorf(message, errorIdentifier)
+	case MessageFirstArguments:
+		return function.Call(jen.Lit(message), errorIdentifier)
+	case ErrorFirstArguments:
+		return function.Call(errorIdentifier, jen.Lit(message))
+	default:
+		return function.Call(jen.Lit(message+": "+wrap.Verb), errorIdentifier)
+	}
+}
+
+// wrapServiceError generates wrapping of the error of the action with the service, for example "close DB".
+// Service-name-aware functions receive the service name and the error wrapped with the action.
+func (params GenerationParameters) wrapServiceError(action, serviceName string, errorIdentifier jen.Code) *jen.Statement {
+	if params.ErrorHandling.Wrap.Arguments == ServiceNameArguments {
+		wrap := params.ErrorHandling.Wrap
+
+		return jen.Qual(wrap.Package, wrap.Function).Call(jen.Lit(serviceName), errorf(action, errorIdentifier))
+	}
+
+	return params.wrapError(action+" "+serviceName, errorIdentifier)
+}
+
+// wrapNamedServiceError generates wrapping of the error of the action with the service name
+// known at runtime, for example "get Repositories.EntityRepository".
+func (params GenerationParameters) wrapNamedServiceError(action string, name, errorIdentifier jen.Code) *jen.Statement {
+	wrap := params.ErrorHandling.Wrap
+	function := jen.Qual(wrap.Package, wrap.Function)
+
+	switch wrap.Arguments {
+	case ServiceNameArguments:
+		return function.Call(name, errorf(action, errorIdentifier))
+	case MessageFirstArguments:
+		return function.Call(jen.Lit(action+" ").Op("+").Add(name), errorIdentifier)
+	case ErrorFirstArguments:
+		return function.Call(errorIdentifier, jen.Lit(action+" ").Op("+").Add(name))
+	default:
+		return function.Call(jen.Lit(action+" %s: "+wrap.Verb), name, errorIdentifier)
+	}
+}
+
+// wrapFormattedError generates wrapping of the error with the message formatted at runtime,
+// for example "item %d".
+func (params GenerationParameters) wrapFormattedError(format string, args []jen.Code, errorIdentifier jen.Code) *jen.Statement {
+	wrap := params.ErrorHandling.Wrap
+	function := jen.Qual(wrap.Package, wrap.Function)
+	message := jen.Qual("fmt", "Sprintf").Call(append([]jen.Code{jen.Lit(format)}, args...)...)
+
+	switch wrap.Arguments {
+	case ServiceNameArguments:
+		arguments := append([]jen.Code{jen.Lit(format + ": %w")}, args...)
+
+		return jen.Qual("fmt", "Errorf").Call(append(arguments, errorIdentifier)...)
+	case MessageFirstArguments:
+		return function.Call(message, errorIdentifier)
+	case ErrorFirstArguments:
+		return function.Call(errorIdentifier, message)
+	default:
+		arguments := append([]jen.Code{jen.Lit(format + ": " + wrap.Verb)}, args...)
+
+		return function.Call(append(arguments, errorIdentifier)...)
+	}
+}
+
+// errorf generates wrapping of the error by fmt.Errorf. Service-name-aware functions accept
+// the service name only, so messages are kept by fmt.Errorf.
+func errorf(message string, errorIdentifier jen.Code) *jen.Statement {
+	return jen.Qual("fmt", "Errorf").Call(jen.Lit(message+": %w"), errorIdentifier)
+}
+
+func (params GenerationParameters) newError(format string, args ...jen.Code) *jen.Statement {
+	options := params.ErrorHandling.New
+	function := jen.Qual(options.Package, options.Function)
+
+	if options.Arguments == MessageFirstArguments {
+		if len(args) == 0 {
+			return function.Call(jen.Lit(format))
+		}
+
+		return function.Call(jen.Qual("fmt", "Sprintf").Call(append([]jen.Code{jen.Lit(format)}, args...)...))
+	}
 
-	return jen.Qual(path, funcName).Call(jen.Lit(message+": "+verb), errorIdentifier)
+	return function.Call(append([]jen.Code{jen.Lit(format)}, args...)...)
 }
 
 func (params GenerationParameters) joinErrors(errs ...jen.Code) *jen.Statement {
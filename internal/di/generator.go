@@ -1,27 +1,37 @@
 package di
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"slices"
+	"strings"
 
 	"github.com/muonsoft/errors"
 	"github.com/spf13/afero"
 	"golang.org/x/mod/modfile"
 )
 
-const (
-	definitionsFile = "internal/definitions/container.go"
-	factoriesDir    = "internal/factories"
-)
-
 type Generator struct {
 	BaseDir    string
 	ModulePath string
 	Params     GenerationParameters
 
+	// DefinitionsFile is the path to the definitions file relative to the base dir,
+	// YAML and JSON files are parsed as declarative definitions. By default, it is
+	// the "container.go" file of the definitions package.
+	DefinitionsFile string
+
+	// SyncFactories enables rewriting of signatures of existing factories mismatching
+	// the service definitions, see FactorySynchronizer.
+	SyncFactories bool
+
 	FS          afero.Fs
 	Logger      Logger
 	FileLocator FileLocator
+	TypeLoader  TypeLoader
+
+	templates *fileTemplates
 }
 
 func (g *Generator) RootPackage() string {
@@ -35,7 +45,7 @@ func (g *Generator) Initialize() error {
 
 	file := &File{
 		Name:    g.FileLocator.GetPackageFilePath(DefinitionsPackage, "container.go"),
-		Content: []byte(definitionsContainerFileSkeleton),
+		Content: []byte(fmt.Sprintf(definitionsContainerFileSkeleton, g.Params.Layout.Definitions.Name)),
 	}
 
 	writer := NewWriter(g.FS)
@@ -59,18 +69,14 @@ func (g *Generator) Generate() error {
 		return err
 	}
 
-	container, err := g.parseDefinitionsFromFile(g.BaseDir + "/" + definitionsFile)
+	container, err := g.parseContainer()
 	if err != nil {
-		return errors.Errorf("parse definitions file: %w", err)
-	}
-	g.Logger.Info("service definitions parsed from:", definitionsFile)
-
-	factories, err := g.parseFactories(container)
-	if err != nil {
-		return errors.Errorf("parse factories: %w", err)
+		return err
 	}
-	if len(factories.Factories) > 0 {
-		container.Factories = factories.Factories
+	if g.SyncFactories {
+		if err := g.syncFactories(container); err != nil {
+			return err
+		}
 	}
 
 	if err := g.generateContainerFiles(container); err != nil {
@@ -79,18 +85,68 @@ func (g *Generator) Generate() error {
 	if err := g.generateFactoriesFiles(container); err != nil {
 		return err
 	}
-	if err := g.generateUtils(); err != nil {
+	if err := g.generateUtils(container); err != nil {
 		return err
 	}
-	if err := g.generateReadmeFile(); err != nil {
+	if err := g.generateReadmeFile(container); err != nil {
 		return err
 	}
+	for _, filename := range g.templates.Unknown() {
+		g.Logger.Warning("template skipped: file", filename, "is not generated")
+	}
 
 	g.Logger.Success("generation completed at dir", g.BaseDir)
 
 	return nil
 }
 
+// Export writes the parsed container model as JSON document, see ExportDocument.
+func (g *Generator) Export(w io.Writer) error {
+	if err := g.init(); err != nil {
+		return err
+	}
+
+	container, err := g.parseContainer()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(NewExporter(g.FileLocator, container, g.Params).Export(), "", "  ")
+	if err != nil {
+		return errors.Errorf("marshal container model: %w", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return errors.Errorf("write container model: %w", err)
+	}
+
+	return nil
+}
+
+// parseContainer parses definitions and existing factories of the container.
+func (g *Generator) parseContainer() (*RootContainerDefinition, error) {
+	container, err := g.parseDefinitionsFromFile(g.BaseDir + "/" + g.DefinitionsFile)
+	if err != nil {
+		return nil, errors.Errorf("parse definitions file: %w", err)
+	}
+	g.Logger.Info("service definitions parsed from:", g.DefinitionsFile)
+
+	if err := g.parseContainerFactories(container); err != nil {
+		return nil, errors.Errorf("parse factories: %w", err)
+	}
+
+	if g.Params.TypeCheck {
+		if err := NewTypeChecker(g.TypeLoader, container).Check(); err != nil {
+			return nil, errors.Errorf("check types: %w", err)
+		}
+		if err := NewMethodResolver(g.TypeLoader, container).Resolve(); err != nil {
+			return nil, errors.Errorf("resolve methods: %w", err)
+		}
+		g.Logger.Info("service definitions type checked")
+	}
+
+	return container, nil
+}
+
 func (g *Generator) init() error {
 	if g.BaseDir == "" {
 		g.BaseDir = "."
@@ -115,27 +171,144 @@ func (g *Generator) init() error {
 	if g.Logger == nil {
 		g.Logger = nilLogger{}
 	}
+	if g.Params.TypeCheck && g.TypeLoader == nil {
+		g.TypeLoader = NewPackagesTypeLoader(g.BaseDir)
+	}
+
+	g.Params = g.Params.Defaults()
+	g.Params.RootPackage = g.RootPackage()
+	if err := g.Params.Layout.Validate(); err != nil {
+		return err
+	}
+	if err := g.Params.Factories.validate(); err != nil {
+		return err
+	}
 
 	g.FileLocator = FileLocator{
 		ContainerDir: g.BaseDir,
 		ModulePath:   g.ModulePath,
+		Layout:       g.Params.Layout,
+	}
+	if g.DefinitionsFile == "" {
+		g.DefinitionsFile = g.Params.Layout.Definitions.Dir + "/container.go"
 	}
 
-	g.Params = g.Params.Defaults()
-	g.Params.RootPackage = g.RootPackage()
+	if !g.Params.Concurrency.IsValid() {
+		return errors.Errorf("%w: concurrency mode %q", ErrNotSupported, g.Params.Concurrency)
+	}
+	if !g.Params.ErrorHandling.Policy.IsValid() {
+		return errors.Errorf("%w: error policy %q", ErrNotSupported, g.Params.ErrorHandling.Policy)
+	}
+	if g.Params.ErrorHandling.Explicit && g.Params.ErrorHandling.Policy == IsolateErrorPolicy {
+		return errors.Errorf("%w: isolate error policy with explicit errors", ErrNotSupported)
+	}
+	if g.Params.ErrorHandling.Explicit && g.Params.Factories.SkipError {
+		// factories must return errors to propagate errors of dependencies
+		return errors.Errorf("%w: factories without errors with explicit errors", ErrNotSupported)
+	}
+	if !g.Params.ErrorHandling.Wrap.Arguments.isValidForWrap() {
+		return errors.Errorf("%w: arguments layout %q of wrap function", ErrNotSupported, g.Params.ErrorHandling.Wrap.Arguments)
+	}
+	if !g.Params.ErrorHandling.New.Arguments.isValidForNew() {
+		return errors.Errorf("%w: arguments layout %q of new function", ErrNotSupported, g.Params.ErrorHandling.New.Arguments)
+	}
+	if g.Params.ErrorHandling.RetryBackoff < 0 {
+		return errors.Errorf("%w: negative retry backoff", ErrNotSupported)
+	}
+	if g.Params.StopTimeout < 0 {
+		return errors.Errorf("%w: negative stop timeout", ErrNotSupported)
+	}
+
+	templates, err := loadFileTemplates(g.FS, g.Params.Templates)
+	if err != nil {
+		return errors.Errorf("load templates: %w", err)
+	}
+	g.templates = templates
 
 	return nil
 }
 
 func (g *Generator) parseDefinitionsFromFile(filename string) (*RootContainerDefinition, error) {
+	if IsDeclarativeDefinitionsFile(filename) {
+		return NewDeclarativeDefinitionsParser(g.FS, g.Logger).ParseFile(filename)
+	}
+
 	parser := NewDefinitionsParser(g.FS, g.Logger)
 
 	return parser.ParseFile(filename)
 }
 
+// parseContainerFactories parses existing factories of the container and resolves their dependencies.
+func (g *Generator) parseContainerFactories(container *RootContainerDefinition) error {
+	factories, err := g.parseFactories(container)
+	if err != nil {
+		return err
+	}
+	if len(factories.Factories) > 0 {
+		container.Factories = factories.Factories
+	}
+	container.FactoryTypes = factories.Types
+
+	return resolveFactoryDependencies(container)
+}
+
 func (g *Generator) parseFactories(container *RootContainerDefinition) (*FactoryDefinitions, error) {
-	dirs := []string{g.BaseDir + "/" + factoriesDir}
+	definitions := NewFactoryDefinitions()
+
+	for _, source := range g.factorySources(container) {
+		factories, err := parseFactoriesFromDirs(g.FS, g.Logger, source.convention, source.dir)
+		if err != nil {
+			return nil, err
+		}
+		definitions.merge(factories)
+	}
+
+	return definitions, nil
+}
+
+// syncFactories rewrites signatures of existing factories, factories are parsed again
+// to generate the container with updated signatures.
+func (g *Generator) syncFactories(container *RootContainerDefinition) error {
+	count, err := NewFactorySynchronizer(g.FS, g.Logger, container, g.Params).Synchronize(g.factorySources(container))
+	if err != nil {
+		return errors.Errorf("sync factories: %w", err)
+	}
+	if count == 0 {
+		return nil
+	}
+	g.Logger.Warning("signatures of", count, "factories changed, check factories marked by", factoryChangedMarker)
+
+	if err := g.parseContainerFactories(container); err != nil {
+		return errors.Errorf("parse factories: %w", err)
+	}
+
+	return nil
+}
+
+// factorySource is the dir of the factories package with the convention of its factories.
+type factorySource struct {
+	dir        string
+	convention factoryConvention
+}
+
+// factorySources returns the default factories package and packages set by the services.
+// Factories of outer packages are functions, only the default package may contain methods of the struct.
+func (g *Generator) factorySources(container *RootContainerDefinition) []factorySource {
+	convention := newFactoryConvention(g.Params)
+	methods := convention
+	methods.receiver = g.Params.Factories.Struct
 
+	sources := []factorySource{{dir: g.BaseDir + "/" + g.Params.Layout.Factories.Dir, convention: methods}}
+	for _, dir := range g.outerFactoryDirs(container) {
+		sources = append(sources, factorySource{dir: dir, convention: convention})
+	}
+
+	return sources
+}
+
+// outerFactoryDirs returns dirs of factory packages set by the services.
+func (g *Generator) outerFactoryDirs(container *RootContainerDefinition) []string {
+	dirs := make([]string, 0)
 	dirVisited := make(map[string]struct{})
 
 	for _, service := range container.Services {
@@ -159,7 +332,7 @@ func (g *Generator) parseFactories(container *RootContainerDefinition) (*Factory
 		}
 	}
 
-	return parseFactoriesFromDirs(g.FS, g.Logger, dirs...)
+	return dirs
 }
 
 func (g *Generator) generateContainerFiles(container *RootContainerDefinition) error {
@@ -172,6 +345,9 @@ func (g *Generator) generateContainerFiles(container *RootContainerDefinition) e
 	writer.Overwrite = true
 
 	for _, file := range files {
+		if err := g.applyTemplates(file, container); err != nil {
+			return err
+		}
 		err = writer.WriteFile(file)
 		if err != nil {
 			return err
@@ -194,14 +370,14 @@ func (g *Generator) generateFactoriesFiles(container *RootContainerDefinition) e
 			continue
 		}
 		writer := NewWriter(g.FS)
-		writer.Append = file.Append
+		writer.Overwrite = file.Append
 		err = writer.WriteFile(file)
 		if err != nil {
 			return err
 		}
 
 		action := "generated"
-		if writer.Append {
+		if file.Append {
 			action = "updated"
 		}
 		g.Logger.Info("factories file", file.Name, action)
@@ -210,30 +386,45 @@ func (g *Generator) generateFactoriesFiles(container *RootContainerDefinition) e
 	return nil
 }
 
-func (g *Generator) generateUtils() error {
-	heading := []byte(fmt.Sprintf(headingTemplate, g.Params.Version))
+func (g *Generator) generateUtils(container *RootContainerDefinition) error {
+	heading := []byte(g.Params.heading())
 
-	file := &File{
-		Name:    g.FileLocator.GetPackageFilePath(InternalPackage, "bitset.go"),
-		Content: slices.Concat(heading, []byte(bitsetSkeleton)),
+	files := []*File{
+		{
+			Name:    g.FileLocator.GetPackageFilePath(InternalPackage, "bitset.go"),
+			Content: slices.Concat(heading, []byte(fmt.Sprintf(bitsetSkeleton, g.Params.Layout.Internal.Name))),
+		},
+	}
+	if g.Params.Concurrency == ServiceConcurrency {
+		files = append(files, &File{
+			Name:    g.FileLocator.GetPackageFilePath(InternalPackage, "sync_bitset.go"),
+			Content: slices.Concat(heading, []byte(fmt.Sprintf(syncBitsetSkeleton, g.Params.Layout.Internal.Name))),
+		})
 	}
 
 	writer := NewWriter(g.FS)
 	writer.Overwrite = true
-	if err := writer.WriteFile(file); err != nil {
-		return err
+	for _, file := range files {
+		if err := g.applyTemplates(file, container); err != nil {
+			return err
+		}
+		if err := writer.WriteFile(file); err != nil {
+			return err
+		}
+		g.Logger.Info("file", file.Name, "generated")
 	}
 
-	g.Logger.Info("file", file.Name, "generated")
-
 	return nil
 }
 
-func (g *Generator) generateReadmeFile() error {
+func (g *Generator) generateReadmeFile(container *RootContainerDefinition) error {
 	file := &File{
 		Name:    g.FileLocator.GetContainerFilePath("README.md"),
 		Content: []byte(readmeTemplate),
 	}
+	if err := g.applyTemplates(file, container); err != nil {
+		return err
+	}
 
 	writer := NewWriter(g.FS)
 	writer.Overwrite = true
@@ -245,3 +436,12 @@ func (g *Generator) generateReadmeFile() error {
 
 	return nil
 }
+
+// applyTemplates renders user templates of the file, see TemplatesParameters.
+func (g *Generator) applyTemplates(file *File, container *RootContainerDefinition) error {
+	return g.templates.Apply(file, TemplateData{
+		Container: container,
+		Params:    g.Params,
+		Filename:  strings.TrimPrefix(file.Name, g.BaseDir+"/"),
+	})
+}
@@ -1,10 +1,13 @@
 package di_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/iancoleman/strcase"
 	"github.com/spf13/afero"
@@ -28,6 +31,11 @@ func TestGenerator_Generate(t *testing.T) {
 		{name: "single container with static type"},
 		{name: "single container with basic types"},
 		{name: "single container with closer"},
+		{name: "custom closer methods"},
+		{
+			name:   "long running services",
+			params: di.GenerationParameters{StopTimeout: 10 * time.Second},
+		},
 		{name: "multiple containers"},
 		{name: "import alias generation"},
 		{name: "override service public name"},
@@ -43,6 +51,200 @@ func TestGenerator_Generate(t *testing.T) {
 				"di/internal/factories/container.go",
 			},
 		},
+		{
+			name: "service concurrency",
+			params: di.GenerationParameters{
+				Concurrency: di.ServiceConcurrency,
+			},
+			testedFiles: append(defaultTestedFiles(), "di/internal/sync_bitset.go"),
+		},
+		{
+			name: "error isolation",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{Policy: di.IsolateErrorPolicy},
+			},
+		},
+		{
+			name: "error isolation with retry backoff",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{
+					Policy:       di.IsolateErrorPolicy,
+					RetryBackoff: 1500 * time.Millisecond,
+				},
+				Concurrency: di.ServiceConcurrency,
+			},
+		},
+		{
+			name: "explicit errors",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{Explicit: true},
+			},
+			testedFiles: append(defaultTestedFiles(),
+				"di/internal/factories/container.go",
+				"di/internal/factories/repositories.go",
+			),
+		},
+		{
+			name: "recover panics",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{RecoverPanics: true},
+				Factories:     di.FactoriesParameters{SkipError: true},
+			},
+		},
+		{
+			name: "error first wrapping",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{
+					New: di.ErrorOptions{
+						Package:   "github.com/pkg/errors",
+						Function:  "New",
+						Arguments: di.MessageFirstArguments,
+					},
+					Wrap: di.ErrorOptions{
+						Package:   "github.com/pkg/errors",
+						Function:  "Wrap",
+						Arguments: di.ErrorFirstArguments,
+					},
+				},
+			},
+		},
+		{
+			name: "service name wrapping",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{
+					Wrap: di.ErrorOptions{
+						Package:   "example.com/test/apperr",
+						Function:  "Service",
+						Arguments: di.ServiceNameArguments,
+					},
+				},
+			},
+		},
+		{
+			name:   "construction hooks",
+			params: di.GenerationParameters{Hooks: true},
+		},
+		{
+			name: "construction hooks with error isolation",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{Policy: di.IsolateErrorPolicy},
+				Hooks:         true,
+			},
+		},
+		{
+			name: "construction hooks with explicit errors",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{Explicit: true},
+				Hooks:         true,
+			},
+		},
+		{
+			name:   "service description",
+			params: di.GenerationParameters{Describe: true},
+		},
+		{
+			name: "service description with hooks",
+			params: di.GenerationParameters{
+				Concurrency: di.ServiceConcurrency,
+				Describe:    true,
+				Hooks:       true,
+			},
+			testedFiles: append(defaultTestedFiles(), "di/internal/sync_bitset.go"),
+		},
+		{
+			name: "named services",
+		},
+		{
+			name: "named services with explicit errors",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{
+					Explicit: true,
+					Wrap: di.ErrorOptions{
+						Package:   "github.com/pkg/errors",
+						Function:  "Wrap",
+						Arguments: di.ErrorFirstArguments,
+					},
+				},
+			},
+		},
+		{
+			name:        "test support",
+			params:      di.GenerationParameters{TestSupport: true},
+			testedFiles: append(defaultTestedFiles(), "di/ditest/container.go"),
+		},
+		{
+			name:        "lookup fakes",
+			params:      di.GenerationParameters{LookupFakes: true},
+			testedFiles: append(defaultTestedFiles(), "di/lookup/lookuptest/container.go"),
+		},
+		{
+			name: "lookup fakes with explicit errors",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{Explicit: true},
+				LookupFakes:   true,
+			},
+			testedFiles: []string{"di/lookup/lookuptest/container.go"},
+		},
+		{
+			name:        "environment parameters",
+			testedFiles: append(defaultTestedFiles(), "di/internal/factories/params.go"),
+		},
+		{
+			name: "environment parameters with error first wrapping",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{
+					Wrap: di.ErrorOptions{
+						Package:   "github.com/pkg/errors",
+						Function:  "Wrap",
+						Arguments: di.ErrorFirstArguments,
+					},
+				},
+			},
+			testedFiles: []string{"di/internal/container.go"},
+		},
+		{
+			name: "environment parameters with service name wrapping",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{
+					Wrap: di.ErrorOptions{
+						Package:   "example.com/test/apperr",
+						Function:  "Service",
+						Arguments: di.ServiceNameArguments,
+					},
+				},
+			},
+			testedFiles: []string{"di/internal/container.go"},
+		},
+		{
+			name:        "literal parameter values",
+			testedFiles: append(defaultTestedFiles(), "di/internal/factories/params.go"),
+		},
+		{
+			name:        "field bindings",
+			testedFiles: append(defaultTestedFiles(), "di/internal/factories/container.go"),
+		},
+		{
+			name: "field bindings with explicit errors",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{Explicit: true},
+			},
+			testedFiles: []string{"di/internal/container.go"},
+		},
+		{
+			name: "factory naming convention",
+			params: di.GenerationParameters{
+				Factories: di.FactoriesParameters{
+					Prefix:        "Provide",
+					SkipContext:   true,
+					ContainerType: "example.com/test/di/contracts.Container",
+				},
+			},
+			testedFiles: []string{
+				"di/internal/container.go",
+				"di/internal/factories/container.go",
+				"di/internal/factories/repositories.go",
+			},
+		},
 		{
 			name: "outer factories",
 			testedFiles: []string{
@@ -51,6 +253,22 @@ func TestGenerator_Generate(t *testing.T) {
 				"pkg/outer_factories/container.go",
 			},
 		},
+		{
+			name: "factories struct",
+			params: di.GenerationParameters{
+				TestSupport: true,
+				Factories: di.FactoriesParameters{
+					Struct: "Factories",
+				},
+			},
+			testedFiles: []string{
+				"di/container.go",
+				"di/internal/container.go",
+				"di/internal/factories/container.go",
+				"di/ditest/container.go",
+				"pkg/outer_factories/container.go",
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -125,3 +343,408 @@ func formatOutputFilename(testCase string, filename string) string {
 
 	return strcase.ToSnake(testCase + "_" + filename)
 }
+
+func TestGenerator_Generate_DeclarativeDefinitions(t *testing.T) {
+	for _, filename := range []string{"service_description.yaml", "service_description.json"} {
+		t.Run(filename, func(t *testing.T) {
+			afs := afero.NewMemMapFs()
+			data, err := os.ReadFile("./testdata/declarative/" + filename)
+			require.NoError(t, err, "read definitions file")
+			err = afero.WriteFile(afs, "./di/"+filename, data, 0644)
+			require.NoError(t, err, "write definitions file")
+
+			generator := &di.Generator{
+				BaseDir:         "di",
+				ModulePath:      "example.com/test",
+				FS:              afs,
+				Params:          di.GenerationParameters{Describe: true},
+				DefinitionsFile: filename,
+			}
+			err = generator.Generate()
+
+			require.NoError(t, err)
+			assertGeneratedFiles(t, afs, "service description", defaultTestedFiles())
+		})
+	}
+}
+
+func TestGenerator_Export(t *testing.T) {
+	tests := []struct {
+		name      string
+		factories string
+	}{
+		{
+			name: "field bindings",
+			factories: `package factories
+
+import (
+	"context"
+
+	"example.com/test/di/lookup"
+	"example.com/test/domain"
+)
+
+func CreateHandler(ctx context.Context, c lookup.Container) *domain.Handler {
+	return domain.NewHandler(c.Params().DSN(ctx), c.Port(ctx), c.Close)
+}
+`,
+		},
+		{name: "environment parameters"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			afs := afero.NewMemMapFs()
+			setupDefinitionsFile(t, afs, test.name)
+			if test.factories != "" {
+				err := afero.WriteFile(afs, "./di/internal/factories/container.go", []byte(test.factories), 0644)
+				require.NoError(t, err, "write factories file")
+			}
+
+			generator := &di.Generator{
+				BaseDir:    "di",
+				ModulePath: "example.com/test",
+				FS:         afs,
+			}
+			var output bytes.Buffer
+			err := generator.Export(&output)
+
+			require.NoError(t, err)
+			filename := "./testdata/export/" + strcase.ToSnake(test.name) + ".json"
+			if needToDump() {
+				require.NoError(t, os.WriteFile(filename, output.Bytes(), 0644), "write exported file")
+			}
+			want, err := os.ReadFile(filename)
+			require.NoError(t, err, "read expected file")
+			assert.Equal(t, string(want), output.String())
+		})
+	}
+}
+
+func TestExportSchema(t *testing.T) {
+	var schema map[string]any
+	err := json.Unmarshal(di.ExportSchema, &schema)
+
+	require.NoError(t, err)
+	assert.Equal(t, "^1\\.[0-9]+$", schema["properties"].(map[string]any)["version"].(map[string]any)["pattern"])
+}
+
+func TestGenerator_Generate_FileTemplates(t *testing.T) {
+	afs := afero.NewMemMapFs()
+	setupDefinitionsFile(t, afs, "file templates")
+	templates := map[string]string{
+		"templates/container.go.tmpl": `{{ replace .Content "sync.Mutex" "sync.RWMutex" }}`,
+		"templates/container_extra.go.tmpl": `import "net/http"
+
+// Services returns names of services of the container.
+func (c *Container) Services() []string {
+	return []string{ {{- range .Container.Services }}"{{ .Name }}", {{ end -}} }
+}
+
+func (c *Container) HTTPHandler(ctx context.Context) http.Handler {
+	return http.NotFoundHandler()
+}
+`,
+		"templates/readme_extra.md.tmpl": "\n## Services\n{{ range .Container.Services }}\n* {{ .Name }}{{ end }}\n",
+	}
+	for filename, content := range templates {
+		require.NoError(t, afero.WriteFile(afs, filename, []byte(content), 0644), "write template")
+	}
+
+	generator := &di.Generator{
+		BaseDir:    "di",
+		ModulePath: "example.com/test",
+		FS:         afs,
+		Params: di.GenerationParameters{
+			Templates: di.TemplatesParameters{
+				Header:    "Copyright 2026 Example Corp.\nLicensed under the MIT License.",
+				BuildTags: "!wasm",
+				Overrides: map[string]string{"container.go": "templates/container.go.tmpl"},
+				Extras: map[string]string{
+					"container.go": "templates/container_extra.go.tmpl",
+					"README.md":    "templates/readme_extra.md.tmpl",
+				},
+			},
+		},
+	}
+	err := generator.Generate()
+
+	require.NoError(t, err)
+	testedFiles := append(defaultTestedFiles(), "di/internal/bitset.go", "di/README.md")
+	if needToDump() {
+		dumpGeneratedFiles(t, afs, "file templates", testedFiles)
+	}
+	assertGeneratedFiles(t, afs, "file templates", testedFiles)
+}
+
+func TestGenerator_Generate_UnsupportedParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		params di.GenerationParameters
+		want   string
+	}{
+		{
+			name: "isolate policy with explicit errors",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{Explicit: true, Policy: di.IsolateErrorPolicy},
+			},
+			want: "not supported: isolate error policy with explicit errors",
+		},
+		{
+			name: "factories without errors with explicit errors",
+			params: di.GenerationParameters{
+				ErrorHandling: di.ErrorHandling{Explicit: true},
+				Factories:     di.FactoriesParameters{SkipError: true},
+			},
+			want: "not supported: factories without errors with explicit errors",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			generator := &di.Generator{
+				BaseDir:    "di",
+				ModulePath: "example.com/test",
+				FS:         afero.NewMemMapFs(),
+				Params:     test.params,
+			}
+
+			err := generator.Generate()
+
+			assert.ErrorIs(t, err, di.ErrNotSupported)
+			assert.EqualError(t, err, test.want)
+		})
+	}
+}
+
+func TestGenerator_Generate_InvalidPackageLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout di.PackageLayout
+		want   string
+	}{
+		{
+			name:   "colliding dirs",
+			layout: di.PackageLayout{Factories: di.PackageOptions{Dir: "lookup"}},
+			want:   `invalid package layout: lookup package collides with factories package in dir "lookup"`,
+		},
+		{
+			name:   "invalid name",
+			layout: di.PackageLayout{Lookup: di.PackageOptions{Name: "go-lookup"}},
+			want:   `invalid package layout: invalid name "go-lookup" of lookup package`,
+		},
+		{
+			name:   "derived invalid name",
+			layout: di.PackageLayout{Factories: di.PackageOptions{Dir: "di-providers"}},
+			want:   `invalid package layout: invalid name "di-providers" of factories package`,
+		},
+		{
+			name:   "dir outside of container",
+			layout: di.PackageLayout{Internal: di.PackageOptions{Dir: "../internal"}},
+			want:   `invalid package layout: invalid dir "../internal" of internal package`,
+		},
+		{
+			name:   "public dir",
+			layout: di.PackageLayout{Public: di.PackageOptions{Dir: "public"}},
+			want:   "invalid package layout: public package is placed into the container dir",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			generator := &di.Generator{
+				BaseDir:    "di",
+				ModulePath: "example.com/test",
+				FS:         afero.NewMemMapFs(),
+				Params:     di.GenerationParameters{Layout: test.layout},
+			}
+
+			err := generator.Generate()
+
+			assert.ErrorIs(t, err, di.ErrInvalidLayout)
+			assert.EqualError(t, err, test.want)
+		})
+	}
+}
+
+func TestGenerator_Generate_AppendFactories(t *testing.T) {
+	const testCase = "append factories"
+	afs := afero.NewMemMapFs()
+	setupDefinitionsFile(t, afs, testCase)
+	factories, err := os.ReadFile("./testdata/factories/append_factories.txt")
+	require.NoError(t, err, "read factories file")
+	err = afero.WriteFile(afs, "./di/internal/factories/container.go", factories, 0644)
+	require.NoError(t, err, "write factories file")
+	generator := &di.Generator{
+		BaseDir:    "di",
+		ModulePath: "example.com/test",
+		FS:         afs,
+	}
+
+	err = generator.Generate()
+
+	require.NoError(t, err)
+	testedFiles := []string{"di/internal/factories/container.go"}
+	if needToDump() {
+		dumpGeneratedFiles(t, afs, testCase, testedFiles)
+	}
+	assertGeneratedFiles(t, afs, testCase, testedFiles)
+}
+
+func TestGenerator_Generate_SyncFactories(t *testing.T) {
+	const testCase = "sync factories"
+	afs := afero.NewMemMapFs()
+	setupDefinitionsFile(t, afs, testCase)
+	factories, err := os.ReadFile("./testdata/factories/sync_factories.txt")
+	require.NoError(t, err, "read factories file")
+	err = afero.WriteFile(afs, "./di/internal/factories/container.go", factories, 0644)
+	require.NoError(t, err, "write factories file")
+	generator := &di.Generator{
+		BaseDir:       "di",
+		ModulePath:    "example.com/test",
+		FS:            afs,
+		SyncFactories: true,
+	}
+
+	err = generator.Generate()
+
+	require.NoError(t, err)
+	testedFiles := []string{
+		"di/internal/container.go",
+		"di/internal/factories/container.go",
+	}
+	if needToDump() {
+		dumpGeneratedFiles(t, afs, testCase, testedFiles)
+	}
+	assertGeneratedFiles(t, afs, testCase, testedFiles)
+}
+
+func TestGenerator_Generate_SyncFactoryResults(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  di.GenerationParameters
+		results string
+		want    string
+	}{
+		{
+			name:    "factory without error is kept when errors are returned",
+			results: "*domain.Clock",
+			want:    "func CreateClock(ctx context.Context, c lookup.Container) *domain.Clock {",
+		},
+		{
+			name:    "factory with error is kept when errors are skipped",
+			params:  di.GenerationParameters{Factories: di.FactoriesParameters{SkipError: true}},
+			results: "(*domain.Clock, error)",
+			want:    "func CreateClock(ctx context.Context, c lookup.Container) (*domain.Clock, error) {",
+		},
+		{
+			name:    "factory without error is kept in explicit mode",
+			params:  di.GenerationParameters{ErrorHandling: di.ErrorHandling{Explicit: true}},
+			results: "*domain.Clock",
+			want:    "func CreateClock(ctx context.Context, c lookup.Container) *domain.Clock {",
+		},
+		{
+			name:    "unsupported results are removed",
+			results: "(*domain.Clock, bool, error)",
+			want: "// TODO(digen): type changed\n" +
+				"func CreateClock(ctx context.Context, c lookup.Container) (*domain.Clock, error) {",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			afs := afero.NewMemMapFs()
+			setupDefinitionsFile(t, afs, "sync factories")
+			factories := "package factories\n\n" +
+				"import (\n\t\"context\"\n\n\t\"example.com/test/di/lookup\"\n\t\"example.com/test/domain\"\n)\n\n" +
+				"func CreateClock(ctx context.Context, c lookup.Container) " + test.results + " {\n\tpanic(\"not implemented\")\n}\n"
+			err := afero.WriteFile(afs, "./di/internal/factories/container.go", []byte(factories), 0644)
+			require.NoError(t, err, "write factories file")
+			generator := &di.Generator{
+				BaseDir:       "di",
+				ModulePath:    "example.com/test",
+				FS:            afs,
+				Params:        test.params,
+				SyncFactories: true,
+			}
+
+			err = generator.Generate()
+
+			require.NoError(t, err)
+			got, err := afero.ReadFile(afs, "./di/internal/factories/container.go")
+			require.NoError(t, err, "read factories file")
+			assert.Contains(t, string(got), test.want)
+		})
+	}
+}
+
+func TestGenerator_Generate_InvalidFactoryParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		factories string
+		want      string
+	}{
+		{
+			name: "unknown dependency",
+			factories: `package factories
+
+import (
+	"context"
+
+	"example.com/test/domain"
+)
+
+func CreateHandler(ctx context.Context, repository domain.EntityRepository) *domain.Handler {
+	panic("not implemented")
+}
+`,
+			want: `parse factories: invalid definition: factory of service Handler: service of parameter "repository" not found`,
+		},
+		{
+			name: "unnamed dependency",
+			factories: `package factories
+
+import (
+	"context"
+
+	"example.com/test/domain"
+)
+
+func CreateHandler(context.Context, domain.EntityRepository) *domain.Handler {
+	panic("not implemented")
+}
+`,
+			want: `parse factories: walk dir "di/internal/factories": parse factory CreateHandler: invalid definition: dependency parameter must be named`,
+		},
+		{
+			name: "dependency on itself",
+			factories: `package factories
+
+import (
+	"context"
+
+	"example.com/test/domain"
+)
+
+func CreateHandler(handler *domain.Handler) *domain.Handler {
+	return handler
+}
+`,
+			want: `parse factories: invalid definition: factory of service Handler: parameter "handler" refers to the service itself`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			afs := afero.NewMemMapFs()
+			setupDefinitionsFile(t, afs, "factory naming convention")
+			err := afero.WriteFile(afs, "./di/internal/factories/container.go", []byte(test.factories), 0644)
+			require.NoError(t, err, "write factories file")
+			generator := &di.Generator{
+				BaseDir:    "di",
+				ModulePath: "example.com/test",
+				FS:         afs,
+			}
+
+			err = generator.Generate()
+
+			assert.ErrorIs(t, err, di.ErrInvalidDefinition)
+			assert.EqualError(t, err, test.want)
+		})
+	}
+}
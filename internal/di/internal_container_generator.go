@@ -1,7 +1,9 @@
 package di
 
 import (
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/dave/jennifer/jen"
 	"github.com/iancoleman/strcase"
@@ -24,13 +26,13 @@ func NewInternalContainerGenerator(
 		params:    params,
 		file: NewFileBuilder(
 			fileLocator.GetPackageFilePath(InternalPackage, "container.go"),
-			"internal",
+			params.Layout.Internal.Name,
 		),
 	}
 }
 
 func (g *InternalContainerGenerator) Generate() (*File, error) {
-	g.file.AddHeading(g.params.Version)
+	g.file.AddHeading(g.params)
 	g.file.AddImportAliases(g.container.Imports)
 
 	g.generateRootContainer()
@@ -38,6 +40,8 @@ func (g *InternalContainerGenerator) Generate() (*File, error) {
 	g.generateGetters()
 	g.generateSetters()
 	g.generateClosers()
+	g.generateRunners()
+	g.generateHelpers()
 
 	return g.file.GetFile()
 }
@@ -46,11 +50,35 @@ func (g *InternalContainerGenerator) generateRootContainer() {
 	serviceIDs := make([]string, 0, len(g.container.Services))
 
 	fields := make([]jen.Code, 0, len(g.container.Services)+len(g.container.Containers)+3)
-	fields = append(fields,
-		jen.Id("errs").Op("[]").Error(),
-		jen.Id("init").Qual("", "bitset"),
-		jen.Line(),
-	)
+	if g.isConcurrent() {
+		fields = append(fields, jen.Id("mu").Qual("sync", "Mutex"))
+	}
+	if g.hasErrorList() {
+		fields = append(fields, jen.Id("errs").Op("[]").Error())
+	}
+	fields = append(fields, jen.Id("init").Qual("", g.bitsetType()))
+	if g.isConcurrent() {
+		fields = append(fields, jen.Id("locks").Index(jen.Lit(g.container.ServicesCount())).Qual("sync", "Mutex"))
+	}
+	if g.hasRetryBackoff() {
+		fields = append(fields, jen.Id("failures").Index(jen.Lit(g.container.ServicesCount())).Id("failure"))
+	}
+	if g.params.Hooks {
+		fields = append(fields, jen.Id("hooks").Id("Hooks"))
+	}
+	if g.params.Describe {
+		fields = append(fields, jen.Id("builds").Id("buildStats"))
+	}
+	if g.hasClosers() {
+		fields = append(fields, jen.Id("closers").Op("[]").Int())
+	}
+	if g.hasRunners() {
+		fields = append(fields, jen.Id("runners").Op("[]").Int())
+	}
+	if g.params.Factories.Struct != "" {
+		fields = append(fields, jen.Id("factories").Add(g.params.factoriesStruct()))
+	}
+	fields = append(fields, jen.Line())
 	for _, service := range g.container.Services {
 		fields = append(fields, jen.
 			Id(strcase.ToLowerCamel(service.Name)).Do(g.container.Type(service.Type)),
@@ -62,9 +90,15 @@ func (g *InternalContainerGenerator) generateRootContainer() {
 		fields = append(fields, jen.Line())
 	}
 	constructorBlocks := make([]jen.Code, 0, 2+len(g.container.Containers))
+	container := jen.Op("&").Id("Container").Op("{}")
+	constructorParams := make([]jen.Code, 0, 1)
+	if g.params.Factories.Struct != "" {
+		container = jen.Op("&").Id("Container").Values(jen.Dict{jen.Id("factories"): jen.Id("factories")})
+		constructorParams = append(constructorParams, jen.Id("factories").Add(g.params.factoriesStruct()))
+	}
 	constructorBlocks = append(constructorBlocks,
-		jen.Id("c").Op(":=").Op("&").Id("Container").Op("{}"),
-		jen.Id("c").Dot("init").Op("=").Make(jen.Id("bitset"), jen.Lit(g.container.ServicesCount()/64+1)),
+		jen.Id("c").Op(":=").Add(container),
+		jen.Id("c").Dot("init").Op("=").Make(jen.Id(g.bitsetType()), jen.Lit(g.container.ServicesCount()/64+1)),
 	)
 
 	for _, container := range g.container.Containers {
@@ -86,11 +120,15 @@ func (g *InternalContainerGenerator) generateRootContainer() {
 
 	constructorBlocks = append(constructorBlocks, jen.Line(), jen.Return(jen.Id("c")))
 	g.file.Add(jen.Func().
-		Id("NewContainer").Params().Op("*").Id("Container").
+		Id("NewContainer").Params(constructorParams...).Op("*").Id("Container").
 		Block(constructorBlocks...),
 	)
 
-	g.addErrorHandlingMethods()
+	if g.isIsolated() {
+		g.addErrorIsolationMethods()
+	} else if g.hasErrorList() {
+		g.addErrorHandlingMethods()
+	}
 }
 
 func (g *InternalContainerGenerator) addServiceIDsDeclarations(serviceIDs []string) {
@@ -152,17 +190,27 @@ func (g *InternalContainerGenerator) writeServiceGetters(services []*ServiceDefi
 	for _, service := range services {
 		block := make([]jen.Code, 0, 2)
 		if !service.IsRequired {
-			block = append(block, g.generateInitBlock(service))
+			if g.isConcurrent() {
+				block = append(block, g.generateLockedInitBlock(service))
+			} else {
+				block = append(block, g.generateInitBlock(service))
+			}
 		}
 
-		block = append(block,
-			jen.Return(jen.Id("c").Dot(strcase.ToLowerCamel(service.Name))),
-		)
+		if g.isExplicit() {
+			block = append(block,
+				jen.Return(jen.Id("c").Dot(strcase.ToLowerCamel(service.Name)), jen.Nil()),
+			)
+		} else {
+			block = append(block,
+				jen.Return(jen.Id("c").Dot(strcase.ToLowerCamel(service.Name))),
+			)
+		}
 
 		getter := jen.Func().Params(jen.Id("c").Op("*").Id(strings.Title(containerName))).
 			Id(service.Title()).
 			Params(jen.Id("ctx").Qual("context", "Context")).
-			Do(g.container.Type(service.Type)).
+			Add(g.getterResults(service)).
 			Block(block...)
 
 		g.file.Add(jen.Line(), getter)
@@ -170,20 +218,19 @@ func (g *InternalContainerGenerator) writeServiceGetters(services []*ServiceDefi
 }
 
 func (g *InternalContainerGenerator) generateInitBlock(service *ServiceDefinition) *jen.Statement {
-	serviceID := service.ID()
-	factoryName := strings.Title(service.Prefix) + service.Title()
-
-	withError := g.params.Factories.ReturnError()
-	if factory, exists := g.container.Factories[factoryName]; exists {
-		withError = factory.ReturnsError
+	if g.isExplicit() {
+		return g.generateExplicitInitBlock(service)
 	}
-
-	factoriesPackage := g.params.packageName(FactoriesPackage)
-	if service.FactoryPackage != "" {
-		factoriesPackage = service.FactoryPackage
+	if g.isIsolated() {
+		return g.generateIsolatedInitBlock(service)
 	}
 
-	block := make([]jen.Code, 0, 2)
+	serviceID := service.ID()
+	factory, withError := g.factoryCall(service, "ctx")
+
+	block := make([]jen.Code, 0, 5)
+	block = append(block, g.extendServicePath(service))
+	block = append(block, g.startBuild(service)...)
 	if withError {
 		block = append(block,
 			jen.Var().Id("err").Error(),
@@ -193,32 +240,426 @@ func (g *InternalContainerGenerator) generateInitBlock(service *ServiceDefinitio
 					jen.Id("err"),
 				).
 				Op("=").
-				Qual(factoriesPackage, "Create"+factoryName).
-				Call(jen.Id("ctx"), jen.Id("c")),
+				Add(factory),
+		)
+		block = append(block, g.finishBuild(service, jen.Err())...)
+		block = append(block,
 			jen.If(
 				jen.Id("err").Op("!=").Nil(),
 			).Block(
 				jen.Id("c").Dot("addError").Call(
-					g.params.wrapError("create "+factoryName, jen.Id("err")),
+					jen.Id("newServiceError").Call(jen.Id("ctx"), jen.Id("err")),
 				),
 			).Else().Block(
-				jen.Id("c").Dot("init").Dot("Set").Call(jen.Id(serviceID)),
+				g.markInitialized(service)...,
 			),
 		)
 	} else {
-		block = append(block,
-			jen.Id("c").Dot(strcase.ToLowerCamel(service.Name)).Op("=").
-				Qual(factoriesPackage, "Create"+factoryName).
-				Call(jen.Id("ctx"), jen.Id("c")),
-			jen.Id("c").Dot("init").Dot("Set").Call(jen.Id(serviceID)),
-		)
+		block = append(block, jen.Id("c").Dot(strcase.ToLowerCamel(service.Name)).Op("=").Add(factory))
+		block = append(block, g.finishBuild(service, jen.Nil())...)
+		block = append(block, g.markInitialized(service)...)
+	}
+
+	noErrors := jen.Id("c").Dot("errs").Op("==").Nil()
+	if g.isConcurrent() {
+		noErrors = jen.Id("c").Dot("Error").Call().Op("==").Nil()
 	}
 
 	return jen.If(jen.Op("!").Id("c").Dot("init").Dot("IsSet").Call(jen.Id(serviceID)).
-		Op("&&").Op("c").Dot("errs").Op("==").Nil()).
+		Op("&&").Add(noErrors)).
 		Block(block...)
 }
 
+// generateExplicitInitBlock generates the initialization block that returns the factory error
+// to the caller. The failed service is not marked as initialized, so it is created again
+// on the next call.
+func (g *InternalContainerGenerator) generateExplicitInitBlock(service *ServiceDefinition) *jen.Statement {
+	field := jen.Id("c").Dot(strcase.ToLowerCamel(service.Name))
+	factory, withError := g.factoryCall(service, "ctx")
+
+	block := make([]jen.Code, 0, 7)
+	block = append(block, g.extendServicePath(service))
+	block = append(block, g.startBuild(service)...)
+	if withError {
+		block = append(block,
+			jen.List(jen.Id("s"), jen.Err()).Op(":=").Add(factory),
+		)
+		block = append(block, g.finishBuild(service, jen.Err())...)
+		block = append(block,
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Id("s"), jen.Id("newServiceError").Call(jen.Id("ctx"), jen.Err())),
+			),
+			field.Clone().Op("=").Id("s"),
+		)
+	} else {
+		block = append(block, field.Clone().Op("=").Add(factory))
+		block = append(block, g.finishBuild(service, jen.Nil())...)
+	}
+	block = append(block, g.markInitialized(service)...)
+
+	return jen.If(jen.Op("!").Id("c").Dot("init").Dot("IsSet").Call(jen.Id(service.ID()))).Block(block...)
+}
+
+// generateIsolatedInitBlock generates the initialization block for the isolate error policy.
+// Errors of the factory and of the dependencies are collected by the context of the service
+// initialization. The failed service is not assigned and not marked as initialized, so it is
+// created again on the next call. The service built despite failed dependencies is closed.
+func (g *InternalContainerGenerator) generateIsolatedInitBlock(service *ServiceDefinition) *jen.Statement {
+	serviceID := service.ID()
+	field := jen.Id("c").Dot(strcase.ToLowerCamel(service.Name))
+	factory, withError := g.factoryCall(service, "initCtx")
+
+	block := make([]jen.Code, 0, 8)
+	block = append(block, g.extendServicePath(service))
+	block = append(block, g.startBuild(service)...)
+	initCtx := jen.Id("initCtx")
+	if !g.usesContext(service) {
+		initCtx = jen.Id("_")
+	}
+	block = append(block,
+		jen.List(initCtx, jen.Id("errs")).Op(":=").Id("WithInitErrors").Call(jen.Id("ctx")),
+	)
+	if withError {
+		block = append(block,
+			jen.List(jen.Id("s"), jen.Err()).Op(":=").Add(factory),
+			jen.If(jen.Id("err").Op("!=").Nil()).Block(
+				jen.Id("errs").Dot("add").Call(jen.Id("newServiceError").Call(jen.Id("ctx"), jen.Id("err"))),
+			),
+		)
+	} else {
+		block = append(block, jen.Id("s").Op(":=").Add(factory))
+	}
+
+	fail := make([]jen.Code, 0, 2)
+	if service.HasCloser {
+		fail = append(fail, g.generateFailedServiceClose(service, withError))
+	}
+	if g.hasRetryBackoff() {
+		fail = append(fail, jen.Id("c").Dot("fail").Call(jen.Id("ctx"), jen.Id(serviceID), jen.Id("initErr")))
+	} else {
+		fail = append(fail, jen.Id("reportError").Call(jen.Id("ctx"), jen.Id("initErr")))
+	}
+	success := append([]jen.Code{field.Op("=").Id("s")}, g.markInitialized(service)...)
+	if g.observesBuilds() {
+		block = append(block, jen.Id("initErr").Op(":=").Id("errs").Dot("Err").Call())
+		block = append(block, g.finishBuild(service, jen.Id("initErr"))...)
+		block = append(block,
+			jen.If(jen.Id("initErr").Op("!=").Nil()).Block(fail...).Else().Block(success...),
+		)
+	} else {
+		block = append(block,
+			jen.If(
+				jen.Id("initErr").Op(":=").Id("errs").Dot("Err").Call(),
+				jen.Id("initErr").Op("!=").Nil(),
+			).Block(fail...).Else().Block(success...),
+		)
+	}
+
+	condition := jen.Op("!").Id("c").Dot("init").Dot("IsSet").Call(jen.Id(serviceID))
+	if g.hasRetryBackoff() {
+		condition = condition.Op("&&").Id("c").Dot("canInit").Call(jen.Id("ctx"), jen.Id(serviceID))
+	}
+
+	return jen.If(condition).Block(block...)
+}
+
+// generateFailedServiceClose generates closing of the service built by the factory when
+// its initialization failed because of dependencies. The error of the closer is joined
+// with the initialization error.
+func (g *InternalContainerGenerator) generateFailedServiceClose(service *ServiceDefinition, withError bool) jen.Code {
+	call, returnsError := g.methodCall(service, jen.Id("s"), service.CloseMethod)
+	closing := jen.Code(call)
+	if returnsError {
+		closing = jen.If(jen.Id("closeErr").Op(":=").Add(call), jen.Id("closeErr").Op("!=").Nil()).Block(
+			jen.Id("initErr").Op("=").Add(g.params.joinErrors(
+				jen.Id("initErr"),
+				g.params.wrapServiceError("close", strings.Title(service.Prefix)+service.Title(), jen.Id("closeErr")),
+			)),
+		)
+	}
+	if !withError {
+		return closing
+	}
+
+	return jen.If(jen.Err().Op("==").Nil()).Block(closing)
+}
+
+// factoryCall generates the call of the service factory and reports whether it returns an error.
+// When panics are recovered, the factory is called via recoverFactory and always returns an error.
+func (g *InternalContainerGenerator) factoryCall(service *ServiceDefinition, ctx string) (*jen.Statement, bool) {
+	if service.HasValue {
+		return valueLiteral(service.Type, service.Value)
+	}
+	if service.EnvVar != "" {
+		return g.envCall(service), true
+	}
+	if service.Source != nil {
+		return g.sourceCall(service, ctx)
+	}
+
+	withError := g.params.Factories.ReturnError()
+	if factory, exists := g.container.Factories[strings.Title(service.Prefix)+service.Title()]; exists {
+		withError = factory.ReturnsError
+	}
+
+	factory := jen.Qual(g.factoryPackage(service), g.params.Factories.Name(service))
+	if g.params.isFactoryMethod(service) {
+		factory = jen.Id("c").Dot("factories").Dot(g.params.Factories.Name(service))
+	}
+	params := g.factoryParams(service)
+	isDefault := len(params) == 2 && params[0].Kind == ContextParam && params[1].Kind == ContainerParam
+	if isDefault && !g.params.ErrorHandling.RecoverPanics {
+		return factory.Call(jen.Id(ctx), jen.Id("c")), withError
+	}
+	if isDefault {
+		if !withError {
+			factory = jen.Id("withoutError").Call(factory)
+		}
+
+		return jen.Id("recoverFactory").Call(jen.Id(ctx), jen.Id("c"), factory), true
+	}
+
+	return g.adaptedFactoryCall(service, factory, params, ctx, withError)
+}
+
+// adaptedFactoryCall generates the call of the factory with a custom signature. Dependencies of the factory
+// are passed as arguments, in explicit mode they are resolved before the call by a function literal.
+func (g *InternalContainerGenerator) adaptedFactoryCall(
+	service *ServiceDefinition,
+	factory *jen.Statement,
+	params []FactoryParam,
+	ctx string,
+	withError bool,
+) (*jen.Statement, bool) {
+	body := make([]jen.Code, 0, len(params)+1)
+	args := make([]jen.Code, 0, len(params))
+	hasDependencies := false
+	for _, param := range params {
+		switch param.Kind {
+		case ContextParam:
+			args = append(args, jen.Id(ctx))
+		case ContainerParam:
+			args = append(args, jen.Id("c"))
+		case DependencyParam:
+			hasDependencies = true
+			getter := g.getterCall(service, param.Service, ctx)
+			if !g.isExplicit() {
+				args = append(args, getter)
+				continue
+			}
+			body = append(body,
+				jen.List(jen.Id(param.Name), jen.Err()).Op(":=").Add(getter),
+				jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Id("s"), jen.Err())),
+			)
+			args = append(args, jen.Id(param.Name))
+		}
+	}
+
+	call := factory.Call(args...)
+	if !g.params.ErrorHandling.RecoverPanics && (!g.isExplicit() || !hasDependencies) {
+		return call, withError
+	}
+	if withError {
+		body = append(body, jen.Return(call))
+	} else {
+		body = append(body, jen.Return(call, jen.Nil()))
+	}
+	results := jen.Params(jen.Id("s").Do(g.container.Type(service.Type)), jen.Err().Error())
+
+	if g.params.ErrorHandling.RecoverPanics {
+		return jen.Id("recoverFactory").Call(
+			jen.Id(ctx),
+			jen.Id("c"),
+			jen.Func().
+				Params(jen.Qual("context", "Context"), jen.Qual(g.params.packageName(LookupPackage), "Container")).
+				Add(results).
+				Block(body...),
+		), true
+	}
+
+	return jen.Func().Params().Add(results).Block(body...).Call(), true
+}
+
+// factoryParams returns parameters of the existing factory or parameters of the generated stub.
+func (g *InternalContainerGenerator) factoryParams(service *ServiceDefinition) []FactoryParam {
+	if factory, exists := g.container.Factories[strings.Title(service.Prefix)+service.Title()]; exists {
+		return factory.Params
+	}
+
+	return g.params.defaultFactoryParams()
+}
+
+// usesContext reports whether the construction of the service uses the context of the getter.
+func (g *InternalContainerGenerator) usesContext(service *ServiceDefinition) bool {
+	if service.Source != nil {
+		return true
+	}
+	if !service.HasFactory() {
+		return false
+	}
+	if g.params.ErrorHandling.RecoverPanics {
+		return true
+	}
+
+	return slices.ContainsFunc(g.factoryParams(service), func(param FactoryParam) bool {
+		return param.Kind != ContainerParam
+	})
+}
+
+// getterCall generates the call of the dependency getter from the container of the service.
+func (g *InternalContainerGenerator) getterCall(service, dependency *ServiceDefinition, ctx string) *jen.Statement {
+	getter := jen.Id("c")
+	if service.Prefix != "" {
+		getter = getter.Dot("Container")
+	}
+	if dependency.Prefix != "" {
+		getter = getter.Dot(strcase.ToLowerCamel(dependency.Prefix))
+	}
+
+	return getter.Dot(dependency.Title()).Call(jen.Id(ctx))
+}
+
+// sourceCall generates reading of the field of the source service for "from" option.
+// Errors of the source service are returned as is, because they are already wrapped by its getter.
+func (g *InternalContainerGenerator) sourceCall(service *ServiceDefinition, ctx string) (*jen.Statement, bool) {
+	getter := g.getterCall(service, service.Source, ctx)
+
+	if !g.isExplicit() {
+		for _, field := range service.SourceFields {
+			getter = getter.Dot(field)
+		}
+
+		return getter, false
+	}
+
+	field := jen.Id("s")
+	for _, name := range service.SourceFields {
+		field = field.Dot(name)
+	}
+	read := jen.Func().
+		Params(jen.Id("s").Do(g.container.Type(service.Source.Type))).
+		Do(g.container.Type(service.Type)).
+		Block(jen.Return(field))
+
+	return jen.Id("bindField").Call(read).Call(getter), true
+}
+
+// envCall generates parsing of the service from the environment variable instead of the factory call.
+func (g *InternalContainerGenerator) envCall(service *ServiceDefinition) *jen.Statement {
+	if service.HasDefault {
+		return jen.Id("parseEnvOrDefault").Call(jen.Lit(service.EnvVar), jen.Lit(service.Default), envParser(service.Type))
+	}
+
+	return jen.Id("parseEnv").Call(jen.Lit(service.EnvVar), envParser(service.Type))
+}
+
+// factoryPackage returns the import path of the package with the service factory.
+func (g *InternalContainerGenerator) factoryPackage(service *ServiceDefinition) string {
+	if service.FactoryPackage != "" {
+		return service.FactoryPackage
+	}
+
+	return g.params.packageName(FactoriesPackage)
+}
+
+// startBuild generates the start of the service construction observed by hooks and by the description of services.
+func (g *InternalContainerGenerator) startBuild(service *ServiceDefinition) []jen.Code {
+	if !g.observesBuilds() {
+		return nil
+	}
+
+	statements := []jen.Code{jen.Id("started").Op(":=").Qual("time", "Now").Call()}
+	if g.params.Hooks {
+		statements = append(statements,
+			jen.Id("ctx").Op("=").Id("c").Dot("startBuild").Call(jen.Id("ctx"), jen.Lit(service.FullName())),
+		)
+	}
+
+	return statements
+}
+
+// finishBuild generates the end of the service construction observed by hooks and by the description of services.
+func (g *InternalContainerGenerator) finishBuild(service *ServiceDefinition, err jen.Code) []jen.Code {
+	statements := make([]jen.Code, 0, 2)
+	if g.params.Hooks {
+		statements = append(statements,
+			jen.Id("c").Dot("finishBuild").Call(jen.Id("ctx"), jen.Lit(service.FullName()), jen.Id("started"), err),
+		)
+	}
+	if g.params.Describe {
+		statements = append(statements,
+			jen.Id("c").Dot("builds").Dot("record").Call(jen.Id(service.ID()), jen.Id("started"), err),
+		)
+	}
+
+	return statements
+}
+
+// extendServicePath generates adding of the service into the resolution path carried by the context.
+// The path is used to describe the chain of dependencies in initialization errors,
+// so it is not extended for literal values that cannot fail.
+func (g *InternalContainerGenerator) extendServicePath(service *ServiceDefinition) jen.Code {
+	if service.HasValue && !service.Type.IsURL() {
+		return jen.Null()
+	}
+
+	return jen.Id("ctx").Op("=").Id("withService").Call(jen.Id("ctx"), jen.Lit(service.FullName()))
+}
+
+// generateLockedInitBlock generates the initialization block guarded by the service lock.
+// Already initialized services are returned without locking. Factories may call getters
+// of other services because the locks are acquired in order of dependencies.
+func (g *InternalContainerGenerator) generateLockedInitBlock(service *ServiceDefinition) *jen.Statement {
+	block := append(g.lockService(service), g.generateInitBlock(service))
+
+	return jen.If(jen.Op("!").Id("c").Dot("init").Dot("IsSet").Call(jen.Id(service.ID()))).Block(block...)
+}
+
+func (g *InternalContainerGenerator) lockService(service *ServiceDefinition) []jen.Code {
+	return []jen.Code{
+		jen.Id("c").Dot("locks").Index(jen.Id(service.ID())).Dot("Lock").Call(),
+		jen.Defer().Id("c").Dot("locks").Index(jen.Id(service.ID())).Dot("Unlock").Call(),
+	}
+}
+
+// withContainerLock wraps statements by the lock of the container bookkeeping (errors, closers, runners)
+// in the concurrent mode.
+func (g *InternalContainerGenerator) withContainerLock(statements ...jen.Code) []jen.Code {
+	if !g.isConcurrent() || len(statements) == 0 {
+		return statements
+	}
+
+	return slices.Concat(
+		[]jen.Code{jen.Id("c").Dot("mu").Dot("Lock").Call()},
+		statements,
+		[]jen.Code{jen.Id("c").Dot("mu").Dot("Unlock").Call()},
+	)
+}
+
+// markInitialized generates statements that mark the service as initialized.
+func (g *InternalContainerGenerator) markInitialized(service *ServiceDefinition) []jen.Code {
+	return append(g.registerService(service), jen.Id("c").Dot("init").Dot("Set").Call(jen.Id(service.ID())))
+}
+
+// registerService generates statements that register services with closers and long-running
+// services in the order of initialization. Closers are called in reverse order, runners
+// are started in direct order.
+func (g *InternalContainerGenerator) registerService(service *ServiceDefinition) []jen.Code {
+	statements := make([]jen.Code, 0, 2)
+	if service.HasCloser {
+		statements = append(statements,
+			jen.Id("c").Dot("closers").Op("=").Append(jen.Id("c").Dot("closers"), jen.Id(service.ID())),
+		)
+	}
+	if service.IsRunnable() {
+		statements = append(statements,
+			jen.Id("c").Dot("runners").Op("=").Append(jen.Id("c").Dot("runners"), jen.Id(service.ID())),
+		)
+	}
+
+	return g.withContainerLock(statements...)
+}
+
 func (g *InternalContainerGenerator) generateSetters() {
 	for _, service := range g.container.Services {
 		g.generateSetter(g.container.Name, service)
@@ -233,79 +674,1057 @@ func (g *InternalContainerGenerator) generateSetters() {
 
 func (g *InternalContainerGenerator) generateSetter(containerName string, service *ServiceDefinition) {
 	if service.HasSetter || service.IsRequired {
+		block := make([]jen.Code, 0, 5)
+		if g.isConcurrent() {
+			block = append(block, g.lockService(service)...)
+			block = append(block, jen.Line())
+		}
+		block = append(block, jen.Id("c").Dot(strcase.ToLowerCamel(service.Name)).Op("=").Id("s"))
+		if registration := g.registerService(service); len(registration) > 0 {
+			block = append(block,
+				jen.If(jen.Op("!").Id("c").Dot("init").Dot("IsSet").Call(jen.Id(service.ID()))).Block(registration...),
+			)
+		}
+		block = append(block, jen.Id("c").Dot("init").Dot("Set").Call(jen.Op(service.ID())))
+
 		setter := jen.Func().
 			Params(jen.Id("c").Op("*").Id(containerName)).
-			Id("Set"+service.Title()).
+			Id("Set" + service.Title()).
 			Params(jen.Id("s").Do(g.container.Type(service.Type))).
-			Block(
-				jen.Id("c").Dot(strcase.ToLowerCamel(service.Name)).Op("=").Id("s"),
-				jen.Id("c").Dot("init").Dot("Set").Call(jen.Op(service.ID())),
-			)
+			Block(block...)
 
 		g.file.Add(jen.Line(), setter)
 	}
 }
 
 func (g *InternalContainerGenerator) generateClosers() {
-	closers := make([]jen.Code, 0, 2)
+	cases := make([]jen.Code, 0, 2)
 
 	for _, service := range g.container.Services {
 		if service.HasCloser {
-			closers = append(closers, g.generateCloser(service, nil))
+			cases = append(cases, g.generateCloser(service, nil))
 		}
 	}
 
 	for _, attachedContainer := range g.container.Containers {
 		for _, service := range attachedContainer.Services {
 			if service.HasCloser {
-				closers = append(closers, g.generateCloser(service, attachedContainer))
+				cases = append(cases, g.generateCloser(service, attachedContainer))
 			}
 		}
 	}
 
+	if len(cases) == 0 {
+		g.file.Add(
+			jen.Line(),
+			jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
+				Id("Close").
+				Params(jen.Id("ctx").Qual("context", "Context")).
+				Error().
+				Block(jen.Return(jen.Nil())),
+		)
+
+		return
+	}
+
 	g.file.Add(
+		jen.Line(),
+		jen.Comment("Close closes initialized services in reverse order of their initialization."),
+		jen.Line(),
+		jen.Comment("Every closer is limited by the context deadline, closers are never run concurrently:"),
+		jen.Line(),
+		jen.Comment("after the deadline the remaining services are skipped with the context error."),
+		jen.Line(),
+		jen.Comment("All closing errors are joined."),
 		jen.Line(),
 		jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
 			Id("Close").
-			Params().
-			Block(closers...),
+			Params(jen.Id("ctx").Qual("context", "Context")).
+			Error().
+			Block(slices.Concat(
+				g.withContainerLock(
+					jen.Id("closers").Op(":=").Id("c").Dot("closers"),
+					jen.Id("c").Dot("closers").Op("=").Nil(),
+				),
+				[]jen.Code{
+					jen.Line(),
+					jen.Id("errs").Op(":=").Make(jen.Op("[]").Error(), jen.Lit(0), jen.Len(jen.Id("closers"))),
+					jen.For(
+						jen.Id("i").Op(":=").Len(jen.Id("closers")).Op("-").Lit(1),
+						jen.Id("i").Op(">=").Lit(0),
+						jen.Id("i").Op("--"),
+					).Block(
+						jen.If(
+							jen.Err().Op(":=").Id("c").Dot("closeService").Call(jen.Id("ctx"), jen.Id("closers").Index(jen.Id("i"))),
+							jen.Err().Op("!=").Nil(),
+						).Block(
+							jen.Id("errs").Op("=").Append(jen.Id("errs"), jen.Err()),
+						),
+					),
+					jen.Line(),
+					jen.Return(g.params.joinErrors(jen.Id("errs").Op("..."))),
+				},
+			)...),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
+			Id("closeService").
+			Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("id").Int()).
+			Error().
+			Block(
+				jen.Switch(jen.Id("id")).Block(cases...),
+				jen.Line(),
+				jen.Return(jen.Nil()),
+			),
+		jen.Line(),
+		jen.Line(),
+		g.generateCloseWithContext(),
 	)
 }
 
 func (g *InternalContainerGenerator) generateCloser(service *ServiceDefinition, container *ContainerDefinition) *jen.Statement {
-	block := jen.Id("c")
-	if container != nil {
-		block = block.Dot(strcase.ToLowerCamel(container.Name))
+	call, returnsError := g.methodCall(service, g.serviceField(service, container), service.CloseMethod)
+	body := []jen.Code{jen.Return(call)}
+	if !returnsError {
+		body = []jen.Code{call, jen.Return(jen.Nil())}
 	}
-	block = block.Dot(strcase.ToLowerCamel(service.Name)).Dot("Close").Call()
+	closer := jen.Func().Params(jen.Id("ctx").Qual("context", "Context")).Error().Block(body...)
 
-	return jen.
-		If(
-			jen.Id("c").Dot("init").Dot("IsSet").Call(jen.Op(service.ID())),
+	return jen.Case(jen.Id(service.ID())).Block(
+		jen.If(
+			jen.Err().Op(":=").Id("closeWithContext").Call(jen.Id("ctx"), closer),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(g.params.wrapServiceError("close", strings.Title(service.Prefix)+service.Title(), jen.Err())),
+		),
+	)
+}
+
+// generateCloseWithContext generates a helper that runs the closer in a separate goroutine
+// so that the caller is not blocked beyond the context deadline. The closer is not started
+// after the deadline, because the abandoned previous closer may still be running.
+func (g *InternalContainerGenerator) generateCloseWithContext() *jen.Statement {
+	return jen.Func().Id("closeWithContext").
+		Params(
+			jen.Id("ctx").Qual("context", "Context"),
+			jen.Id("closer").Func().Params(jen.Qual("context", "Context")).Error(),
 		).
-		Block(block)
+		Error().
+		Block(
+			jen.If(jen.Err().Op(":=").Id("ctx").Dot("Err").Call(), jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Err()),
+			),
+			jen.Line(),
+			jen.Id("done").Op(":=").Make(jen.Chan().Error(), jen.Lit(1)),
+			jen.Go().Func().Params().Block(
+				jen.Id("done").Op("<-").Id("closer").Call(jen.Id("ctx")),
+			).Call(),
+			jen.Line(),
+			jen.Select().Block(
+				jen.Case(jen.Err().Op(":=").Op("<-").Id("done")).Block(
+					jen.Return(jen.Err()),
+				),
+				jen.Case(jen.Op("<-").Id("ctx").Dot("Done").Call()).Block(
+					jen.Return(jen.Id("ctx").Dot("Err").Call()),
+				),
+			),
+		)
 }
 
-func (g *InternalContainerGenerator) addErrorHandlingMethods() *jen.Statement {
-	return g.file.Add(
+// methodCall generates the call of the close, start or stop method on the receiver by its signature
+// resolved by MethodResolver, the context is passed by "ctx" variable.
+func (g *InternalContainerGenerator) methodCall(
+	service *ServiceDefinition,
+	receiver *jen.Statement,
+	method string,
+) (*jen.Statement, bool) {
+	signature := service.Methods[method]
+	arguments := make([]jen.Code, 0, 1)
+	if signature.WithContext {
+		arguments = append(arguments, jen.Id("ctx"))
+	}
+
+	return receiver.Dot(method).Call(arguments...), signature.ReturnsError
+}
+
+func (g *InternalContainerGenerator) generateRunners() {
+	if !g.hasRunners() {
+		return
+	}
+
+	initializers := make([]jen.Code, 0)
+	cases := make([]jen.Code, 0)
+	for _, service := range g.container.Services {
+		if service.IsRunnable() {
+			initializers = append(initializers, g.generateRunnerInitializer(jen.Id("c").Dot(service.Title()).Call(jen.Id("ctx"))))
+			cases = append(cases, g.generateRunner(service, nil))
+		}
+	}
+	for _, attachedContainer := range g.container.Containers {
+		for _, service := range attachedContainer.Services {
+			if service.IsRunnable() {
+				initializers = append(initializers, g.generateRunnerInitializer(
+					jen.Id("c").Dot(strcase.ToLowerCamel(attachedContainer.Name)).Dot(service.Title()).Call(jen.Id("ctx")),
+				))
+				cases = append(cases, g.generateRunner(service, attachedContainer))
+			}
+		}
+	}
+
+	results := jen.Index().Id("Runner")
+	returnRunners := jen.Return(jen.Id("runners"))
+	if g.isExplicit() {
+		results = jen.Params(jen.Index().Id("Runner"), jen.Error())
+		returnRunners = jen.Return(jen.Id("runners"), jen.Nil())
+	}
+
+	g.file.Add(
 		jen.Line(),
-		jen.Commentf("Error returns the first initialization error, which can be set via SetError in a service definition."),
+		jen.Comment("Runner is a long-running service that is started and stopped by Run function."),
 		jen.Line(),
-		jen.Func().
-			Params(jen.Id("c").Op("*").Id("Container")).
-			Id("Error").Params().Error().
-			Block(jen.Return(g.params.joinErrors(jen.Id("c").Dot("errs").Op("...")))),
+		jen.Type().Id("Runner").Struct(
+			jen.Id("Start").Func().Params(jen.Id("ctx").Qual("context", "Context")).Error(),
+			jen.Id("Stop").Func().Params(jen.Id("ctx").Qual("context", "Context")).Error(),
+		),
 		jen.Line(),
-		jen.Commentf("SetError sets the first error into container. The error is used in the public container to return an initialization error."),
 		jen.Line(),
-		jen.Commentf("Deprecated. Return error in factory instead."),
+		jen.Comment("Runners initializes long-running services and returns them in order of initialization."),
 		jen.Line(),
-		jen.Func().
-			Params(jen.Id("c").Op("*").Id("Container")).
-			Id("SetError").Params(jen.Err().Error()).
-			Block(
-				jen.Id("c").Dot("addError").Call(jen.Err()),
-			),
+		jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
+			Id("Runners").
+			Params(jen.Id("ctx").Qual("context", "Context")).
+			Add(results).
+			Block(slices.Concat(
+				initializers,
+				[]jen.Code{jen.Line()},
+				g.withContainerLock(
+					jen.Id("ids").Op(":=").Id("c").Dot("runners"),
+				),
+				[]jen.Code{
+					jen.Id("runners").Op(":=").Make(jen.Index().Id("Runner"), jen.Lit(0), jen.Len(jen.Id("ids"))),
+					jen.For(jen.List(jen.Id("_"), jen.Id("id")).Op(":=").Range().Id("ids")).Block(
+						jen.Id("runners").Op("=").Append(jen.Id("runners"), jen.Id("c").Dot("runner").Call(jen.Id("id"))),
+					),
+					jen.Line(),
+					returnRunners,
+				},
+			)...),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
+			Id("runner").
+			Params(jen.Id("id").Int()).
+			Id("Runner").
+			Block(
+				jen.Switch(jen.Id("id")).Block(cases...),
+				jen.Line(),
+				jen.Return(jen.Id("Runner").Values()),
+			),
+		jen.Line(),
+		jen.Line(),
+		g.generateRun(),
+	)
+}
+
+func (g *InternalContainerGenerator) generateRunnerInitializer(getter *jen.Statement) jen.Code {
+	if !g.isExplicit() {
+		return getter
+	}
+
+	return jen.If(
+		jen.List(jen.Id("_"), jen.Err()).Op(":=").Add(getter),
+		jen.Err().Op("!=").Nil(),
+	).Block(
+		jen.Return(jen.Nil(), jen.Err()),
+	)
+}
+
+func (g *InternalContainerGenerator) generateRunner(service *ServiceDefinition, container *ContainerDefinition) *jen.Statement {
+	name := strings.Title(service.Prefix) + service.Title()
+	values := jen.Dict{
+		jen.Id("Start"): g.generateRunnerMethod("start", name, service, container, service.StartMethod),
+	}
+	if service.StopMethod != "" {
+		values[jen.Id("Stop")] = g.generateRunnerMethod("stop", name, service, container, service.StopMethod)
+	}
+
+	return jen.Case(jen.Id(service.ID())).Block(
+		jen.Return(jen.Id("Runner").Values(values)),
+	)
+}
+
+func (g *InternalContainerGenerator) generateRunnerMethod(
+	action, serviceName string,
+	service *ServiceDefinition,
+	container *ContainerDefinition,
+	method string,
+) *jen.Statement {
+	call, returnsError := g.methodCall(service, g.serviceField(service, container), method)
+	if !returnsError {
+		return jen.Func().Params(jen.Id("ctx").Qual("context", "Context")).Error().Block(
+			call,
+			jen.Return(jen.Nil()),
+		)
+	}
+
+	return jen.Func().Params(jen.Id("ctx").Qual("context", "Context")).Error().Block(
+		jen.If(
+			jen.Err().Op(":=").Add(call),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(g.params.wrapServiceError(action, serviceName, jen.Err())),
+		),
+		jen.Line(),
+		jen.Return(jen.Nil()),
+	)
+}
+
+// generateRun generates a function that works like an errgroup over long-running services:
+// runners are started in order of initialization and stopped in reverse order when the
+// context is cancelled or any of the runners fails.
+func (g *InternalContainerGenerator) generateRun() *jen.Statement {
+	return jen.Comment("stopTimeout bounds the stop of runners by Run.").
+		Line().
+		Const().Id("stopTimeout").Op("=").Add(durationLiteral(g.params.StopTimeout)).
+		Line().
+		Line().
+		Comment("Run starts runners and blocks until the context is cancelled or any runner fails.").
+		Line().
+		Comment("Then runners are stopped in reverse order, the stop is bounded by stopTimeout. Errors of runners").
+		Line().
+		Comment("finished after the stop are ignored. Returns the combined error of the failed runner and stop methods.").
+		Line().
+		Func().Id("Run").
+		Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("runners").Index().Id("Runner")).
+		Error().
+		Block(
+			jen.List(jen.Id("ctx"), jen.Id("cancel")).Op(":=").Qual("context", "WithCancel").Call(jen.Id("ctx")),
+			jen.Defer().Id("cancel").Call(),
+			jen.Line(),
+			jen.Id("results").Op(":=").Make(jen.Chan().Error(), jen.Len(jen.Id("runners"))),
+			jen.For(jen.List(jen.Id("_"), jen.Id("runner")).Op(":=").Range().Id("runners")).Block(
+				jen.Go().Func().Params(jen.Id("runner").Id("Runner")).Block(
+					jen.Id("results").Op("<-").Id("runner").Dot("Start").Call(jen.Id("ctx")),
+				).Call(jen.Id("runner")),
+			),
+			jen.Line(),
+			jen.Id("errs").Op(":=").Make(jen.Index().Error(), jen.Lit(0), jen.Len(jen.Id("runners")).Op("+").Lit(1)),
+			jen.Id("running").Op(":=").Len(jen.Id("runners")),
+			jen.Id("wait").Op(":"),
+			jen.For(jen.Id("running").Op(">").Lit(0)).Block(
+				jen.Select().Block(
+					jen.Case(jen.Op("<-").Id("ctx").Dot("Done").Call()).Block(
+						jen.Break().Id("wait"),
+					),
+					jen.Case(jen.Err().Op(":=").Op("<-").Id("results")).Block(
+						jen.Id("running").Op("--"),
+						jen.If(jen.Err().Op("!=").Nil()).Block(
+							jen.Id("errs").Op("=").Append(jen.Id("errs"), jen.Err()),
+							jen.Break().Id("wait"),
+						),
+					),
+				),
+			),
+			jen.Id("cancel").Call(),
+			jen.Line(),
+			jen.List(jen.Id("stopCtx"), jen.Id("stopCancel")).Op(":=").Qual("context", "WithTimeout").Call(
+				jen.Qual("context", "WithoutCancel").Call(jen.Id("ctx")),
+				jen.Id("stopTimeout"),
+			),
+			jen.Defer().Id("stopCancel").Call(),
+			jen.For(
+				jen.Id("i").Op(":=").Len(jen.Id("runners")).Op("-").Lit(1),
+				jen.Id("i").Op(">=").Lit(0),
+				jen.Id("i").Op("--"),
+			).Block(
+				jen.If(jen.Id("runners").Index(jen.Id("i")).Dot("Stop").Op("==").Nil()).Block(
+					jen.Continue(),
+				),
+				jen.If(
+					jen.Err().Op(":=").Id("runners").Index(jen.Id("i")).Dot("Stop").Call(jen.Id("stopCtx")),
+					jen.Err().Op("!=").Nil(),
+				).Block(
+					jen.Id("errs").Op("=").Append(jen.Id("errs"), jen.Err()),
+				),
+			),
+			jen.For(jen.Id("running").Op(">").Lit(0)).Block(
+				jen.Op("<-").Id("results"),
+				jen.Id("running").Op("--"),
+			),
+			jen.Line(),
+			jen.Return(g.params.joinErrors(jen.Id("errs").Op("..."))),
+		)
+}
+
+// generateHelpers generates functions shared by getters, closers and runners.
+func (g *InternalContainerGenerator) generateHelpers() {
+	g.generateServiceError()
+	if g.container.HasService(func(service *ServiceDefinition) bool { return service.EnvVar != "" }) {
+		g.generateEnvParsers()
+	}
+	if g.isExplicit() && g.container.HasService(func(service *ServiceDefinition) bool { return service.Source != nil }) {
+		g.generateBindField()
+	}
+	if g.params.ErrorHandling.RecoverPanics {
+		g.generatePanicRecovery()
+	}
+	if g.params.Hooks {
+		g.generateHooks()
+	}
+	if g.hasNamedServices() {
+		g.generateNamedLookup()
+	}
+	if g.params.TestSupport {
+		g.generateOverride()
+	}
+	if g.params.Describe {
+		g.generateDescription()
+	}
+}
+
+// generateOverride generates replacing of services by fakes for the ditest package.
+// Overridden services are marked as initialized, but they are neither closed nor started.
+func (g *InternalContainerGenerator) generateOverride() {
+	cases := make([]jen.Code, 0, g.container.ServicesCount()+1)
+	add := func(service *ServiceDefinition, container *ContainerDefinition) {
+		if service.IsRequired {
+			return
+		}
+		cases = append(cases, jen.Case(jen.Lit(service.FullName())).Block(
+			jen.List(jen.Id("service"), jen.Id("ok")).Op(":=").Id("s").Assert(jen.Null().Do(g.container.Type(service.Type))),
+			jen.If(jen.Op("!").Id("ok")).Block(
+				jen.Return(g.params.newError("unexpected type %T of service "+service.FullName(), jen.Id("s"))),
+			),
+			g.serviceField(service, container).Op("=").Id("service"),
+			jen.Id("c").Dot("init").Dot("Set").Call(jen.Id(service.ID())),
+		))
+	}
+	for _, service := range g.container.Services {
+		add(service, nil)
+	}
+	for _, container := range g.container.Containers {
+		for _, service := range container.Services {
+			add(service, container)
+		}
+	}
+	cases = append(cases, jen.Default().Block(
+		jen.Return(g.params.newError("service %s cannot be overridden", jen.Id("name"))),
+	))
+
+	g.file.Add(
+		jen.Line(),
+		jen.Comment("Override replaces the service by the name with the fake, the fake is neither closed nor started."),
+		jen.Line(),
+		jen.Comment("It is used by the generated ditest package."),
+		jen.Line(),
+		jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
+			Id("Override").
+			Params(jen.Id("name").String(), jen.Id("s").Any()).
+			Error().
+			Block(
+				jen.Switch(jen.Id("name")).Block(cases...),
+				jen.Line(),
+				jen.Return(jen.Nil()),
+			),
+	)
+}
+
+// generateNamedLookup generates resolving of services by name. Only services with the "named" option
+// are available, the name is the service name qualified by the name of attached container.
+func (g *InternalContainerGenerator) generateNamedLookup() {
+	ids := make(jen.Dict)
+	cases := make([]jen.Code, 0)
+	add := func(service *ServiceDefinition, container *ContainerDefinition) {
+		if !service.IsNamed {
+			return
+		}
+		ids[jen.Lit(service.FullName())] = jen.Id(service.ID())
+
+		getter := jen.Id("c")
+		if container != nil {
+			getter = getter.Dot(strcase.ToLowerCamel(container.Name))
+		}
+		getter = getter.Dot(service.Title()).Call(jen.Id("ctx"))
+		if g.isExplicit() {
+			cases = append(cases, jen.Case(jen.Id(service.ID())).Block(jen.Return(getter)))
+		} else {
+			cases = append(cases, jen.Case(jen.Id(service.ID())).Block(jen.Return(getter, jen.Nil())))
+		}
+	}
+	for _, service := range g.container.Services {
+		add(service, nil)
+	}
+	for _, container := range g.container.Containers {
+		for _, service := range container.Services {
+			add(service, container)
+		}
+	}
+
+	g.file.Add(
+		jen.Line(),
+		jen.Comment("ErrServiceNotFound is returned by Get when the service does not exist or is not available by name."),
+		jen.Line(),
+		jen.Var().Id("ErrServiceNotFound").Op("=").Qual("errors", "New").Call(jen.Lit("service not found")),
+		jen.Line(),
+		jen.Line(),
+		jen.Comment("serviceIDs maps names of services available by name to their identifiers."),
+		jen.Line(),
+		jen.Var().Id("serviceIDs").Op("=").Map(jen.String()).Int().Values(ids),
+		jen.Line(),
+		jen.Line(),
+		jen.Comment("Get returns the service by the name, only services with the \"named\" option are available."),
+		jen.Line(),
+		jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
+			Id("Get").
+			Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("name").String()).
+			Params(jen.Any(), jen.Error()).
+			Block(
+				jen.List(jen.Id("id"), jen.Id("ok")).Op(":=").Id("serviceIDs").Index(jen.Id("name")),
+				jen.If(jen.Op("!").Id("ok")).Block(
+					jen.Return(jen.Nil(), jen.Id("ErrServiceNotFound")),
+				),
+				jen.Line(),
+				jen.Switch(jen.Id("id")).Block(cases...),
+				jen.Line(),
+				jen.Return(jen.Nil(), jen.Id("ErrServiceNotFound")),
+			),
+	)
+}
+
+// generateHooks generates the interface of hooks observing construction of services
+// and methods notifying them. Hooks are optional and set by the public container injector.
+func (g *InternalContainerGenerator) generateHooks() {
+	g.file.Add(
+		jen.Line(),
+		jen.Comment("Hooks observes construction of services. OnBuildStart is called before the service factory,"),
+		jen.Line(),
+		jen.Comment("the returned context is passed to the factory and to OnBuildDone."),
+		jen.Line(),
+		jen.Type().Id("Hooks").Interface(
+			jen.Id("OnBuildStart").
+				Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("id").String()).
+				Qual("context", "Context"),
+			jen.Id("OnBuildDone").
+				Params(
+					jen.Id("ctx").Qual("context", "Context"),
+					jen.Id("id").String(),
+					jen.Id("duration").Qual("time", "Duration"),
+					jen.Err().Error(),
+				),
+		),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
+			Id("SetHooks").
+			Params(jen.Id("hooks").Id("Hooks")).
+			Block(
+				jen.Id("c").Dot("hooks").Op("=").Id("hooks"),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
+			Id("startBuild").
+			Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("id").String()).
+			Qual("context", "Context").
+			Block(
+				jen.If(jen.Id("c").Dot("hooks").Op("==").Nil()).Block(
+					jen.Return(jen.Id("ctx")),
+				),
+				jen.Line(),
+				jen.Return(jen.Id("c").Dot("hooks").Dot("OnBuildStart").Call(jen.Id("ctx"), jen.Id("id"))),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
+			Id("finishBuild").
+			Params(
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("id").String(),
+				jen.Id("started").Qual("time", "Time"),
+				jen.Err().Error(),
+			).
+			Block(
+				jen.If(jen.Id("c").Dot("hooks").Op("!=").Nil()).Block(
+					jen.Id("c").Dot("hooks").Dot("OnBuildDone").Call(
+						jen.Id("ctx"),
+						jen.Id("id"),
+						jen.Qual("time", "Since").Call(jen.Id("started")),
+						jen.Err(),
+					),
+				),
+			),
+	)
+}
+
+// generatePanicRecovery generates helpers that convert panics of factories into errors,
+// so the panic is handled by the error policy like an error returned by the factory.
+func (g *InternalContainerGenerator) generatePanicRecovery() {
+	factory := func(results ...jen.Code) *jen.Statement {
+		return jen.Func().
+			Params(jen.Qual("context", "Context"), jen.Qual(g.params.packageName(LookupPackage), "Container")).
+			Params(results...)
+	}
+
+	g.file.Add(
+		jen.Line(),
+		jen.Comment("PanicError is the panic recovered from the service factory."),
+		jen.Line(),
+		jen.Type().Id("PanicError").Struct(
+			jen.Id("Value").Any(),
+			jen.Id("Stack").Index().Byte(),
+		),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Params(jen.Id("e").Op("*").Id("PanicError")).
+			Id("Error").Params().String().
+			Block(
+				jen.Return(jen.Qual("fmt", "Sprintf").Call(jen.Lit("panic: %v"), jen.Id("e").Dot("Value"))),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Id("recoverFactory").
+			Types(jen.Id("T").Any()).
+			Params(
+				jen.Id("ctx").Qual("context", "Context"),
+				jen.Id("c").Qual(g.params.packageName(LookupPackage), "Container"),
+				jen.Id("factory").Add(factory(jen.Id("T"), jen.Error())),
+			).
+			Params(jen.Id("s").Id("T"), jen.Err().Error()).
+			Block(
+				jen.Defer().Func().Params().Block(
+					jen.If(
+						jen.Id("recovered").Op(":=").Recover(),
+						jen.Id("recovered").Op("!=").Nil(),
+					).Block(
+						jen.Err().Op("=").Op("&").Id("PanicError").Values(jen.Dict{
+							jen.Id("Value"): jen.Id("recovered"),
+							jen.Id("Stack"): jen.Qual("runtime/debug", "Stack").Call(),
+						}),
+					),
+				).Call(),
+				jen.Line(),
+				jen.Return(jen.Id("factory").Call(jen.Id("ctx"), jen.Id("c"))),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Id("withoutError").
+			Types(jen.Id("T").Any()).
+			Params(jen.Id("factory").Add(factory(jen.Id("T")))).
+			Add(factory(jen.Id("T"), jen.Error())).
+			Block(
+				jen.Return(
+					jen.Func().
+						Params(
+							jen.Id("ctx").Qual("context", "Context"),
+							jen.Id("c").Qual(g.params.packageName(LookupPackage), "Container"),
+						).
+						Params(jen.Id("T"), jen.Error()).
+						Block(
+							jen.Return(jen.Id("factory").Call(jen.Id("ctx"), jen.Id("c")), jen.Nil()),
+						),
+				),
+			),
+	)
+}
+
+// generateServiceError generates the error type of the service initialization and helpers
+// to track the chain of services resolved by the context.
+func (g *InternalContainerGenerator) generateServiceError() {
+	g.file.Add(
+		jen.Line(),
+		jen.Comment("ServiceError is the initialization error of the service. Path contains the chain of services"),
+		jen.Line(),
+		jen.Comment("from the requested one to the failed one."),
+		jen.Line(),
+		jen.Type().Id("ServiceError").Struct(
+			jen.Id("ID").String(),
+			jen.Id("Path").Index().String(),
+			jen.Id("Err").Error(),
+		),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Params(jen.Id("e").Op("*").Id("ServiceError")).
+			Id("Error").Params().String().
+			Block(
+				jen.Return(
+					jen.Lit("create ").
+						Op("+").Qual("strings", "Join").Call(jen.Id("e").Dot("Path"), jen.Lit(" -> ")).
+						Op("+").Lit(": ").
+						Op("+").Id("e").Dot("Err").Dot("Error").Call(),
+				),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Params(jen.Id("e").Op("*").Id("ServiceError")).
+			Id("Unwrap").Params().Error().
+			Block(jen.Return(jen.Id("e").Dot("Err"))),
+		jen.Line(),
+		jen.Line(),
+		jen.Comment("newServiceError wraps the factory error of the service resolved by the context."),
+		jen.Line(),
+		jen.Comment("Errors of dependencies already contain the full path, so they are returned as is."),
+		jen.Line(),
+		jen.Func().Id("newServiceError").
+			Params(jen.Id("ctx").Qual("context", "Context"), jen.Err().Error()).
+			Error().
+			Block(
+				jen.Var().Id("serviceErr").Op("*").Id("ServiceError"),
+				jen.If(jen.Qual("errors", "As").Call(jen.Err(), jen.Op("&").Id("serviceErr"))).Block(
+					jen.Return(jen.Err()),
+				),
+				jen.Line(),
+				jen.Id("path").Op(":=").Id("servicePath").Call(jen.Id("ctx")),
+				jen.Line(),
+				jen.Return(jen.Op("&").Id("ServiceError").Values(jen.Dict{
+					jen.Id("ID"):   jen.Id("path").Index(jen.Len(jen.Id("path")).Op("-").Lit(1)),
+					jen.Id("Path"): jen.Id("path"),
+					jen.Id("Err"):  jen.Err(),
+				})),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Type().Id("servicePathKey").Struct(),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Id("servicePath").
+			Params(jen.Id("ctx").Qual("context", "Context")).
+			Index().String().
+			Block(
+				jen.List(jen.Id("path"), jen.Id("_")).Op(":=").Id("ctx").Dot("Value").Call(jen.Id("servicePathKey").Values()).
+					Assert(jen.Index().String()),
+				jen.Line(),
+				jen.Return(jen.Id("path")),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Comment("withService adds the service into the resolution path carried by the context."),
+		jen.Line(),
+		jen.Func().Id("withService").
+			Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("name").String()).
+			Qual("context", "Context").
+			Block(
+				jen.Id("path").Op(":=").Id("servicePath").Call(jen.Id("ctx")),
+				jen.Line(),
+				jen.Return(jen.Qual("context", "WithValue").Call(
+					jen.Id("ctx"),
+					jen.Id("servicePathKey").Values(),
+					jen.Append(jen.Id("path").Index(jen.Op(":").Len(jen.Id("path")).Op(":").Len(jen.Id("path"))), jen.Id("name")),
+				)),
+			),
+	)
+}
+
+// generateEnvParsers generates parsing of services from environment variables.
+// Parsers are generated only for the types of the services.
+func (g *InternalContainerGenerator) generateEnvParsers() {
+	parser := jen.Func().Params(jen.String()).Params(jen.Id("T"), jen.Error())
+
+	g.file.Add(
+		jen.Line(),
+		jen.Comment("parseEnv parses the service from the environment variable, the variable must be set."),
+		jen.Line(),
+		jen.Func().Id("parseEnv").
+			Types(jen.Id("T").Any()).
+			Params(jen.Id("name").String(), jen.Id("parse").Add(parser.Clone())).
+			Params(jen.Id("T"), jen.Error()).
+			Block(
+				jen.List(jen.Id("value"), jen.Id("ok")).Op(":=").Qual("os", "LookupEnv").Call(jen.Id("name")),
+				jen.If(jen.Op("!").Id("ok")).Block(
+					jen.Var().Id("zero").Id("T"),
+					jen.Return(jen.Id("zero"), g.params.newError("environment variable %s is not set", jen.Id("name"))),
+				),
+				jen.Line(),
+				jen.Return(jen.Id("parseEnvValue").Call(jen.Id("name"), jen.Id("value"), jen.Id("parse"))),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Comment("parseEnvOrDefault parses the service from the environment variable or from the default value."),
+		jen.Line(),
+		jen.Func().Id("parseEnvOrDefault").
+			Types(jen.Id("T").Any()).
+			Params(jen.List(jen.Id("name"), jen.Id("defaultValue")).String(), jen.Id("parse").Add(parser.Clone())).
+			Params(jen.Id("T"), jen.Error()).
+			Block(
+				jen.List(jen.Id("value"), jen.Id("ok")).Op(":=").Qual("os", "LookupEnv").Call(jen.Id("name")),
+				jen.If(jen.Op("!").Id("ok")).Block(
+					jen.Id("value").Op("=").Id("defaultValue"),
+				),
+				jen.Line(),
+				jen.Return(jen.Id("parseEnvValue").Call(jen.Id("name"), jen.Id("value"), jen.Id("parse"))),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Comment("parseEnvValue parses the value and adds the name of the environment variable into the error."),
+		jen.Line(),
+		jen.Func().Id("parseEnvValue").
+			Types(jen.Id("T").Any()).
+			Params(jen.List(jen.Id("name"), jen.Id("value")).String(), jen.Id("parse").Add(parser.Clone())).
+			Params(jen.Id("T"), jen.Error()).
+			Block(
+				jen.List(jen.Id("s"), jen.Err()).Op(":=").Id("parse").Call(jen.Id("value")),
+				jen.If(jen.Err().Op("!=").Nil()).Block(
+					jen.Return(jen.Id("s"), g.params.wrapFormattedError("invalid %s", []jen.Code{jen.Id("name")}, jen.Err())),
+				),
+				jen.Line(),
+				jen.Return(jen.Id("s"), jen.Nil()),
+			),
+	)
+
+	generated := make(map[string]bool)
+	hasSlices := false
+	add := func(service *ServiceDefinition) {
+		if service.EnvVar == "" {
+			return
+		}
+		hasSlices = hasSlices || service.Type.IsSlice
+		element := service.Type
+		element.IsSlice = false
+		name := envParserName(element)
+		if generated[name] {
+			return
+		}
+		generated[name] = true
+		g.file.Add(jen.Line(), jen.Line(), generateEnvParser(name, element))
+	}
+	for _, service := range g.container.Services {
+		add(service)
+	}
+	for _, container := range g.container.Containers {
+		for _, service := range container.Services {
+			add(service)
+		}
+	}
+
+	if hasSlices {
+		g.file.Add(
+			jen.Line(),
+			jen.Line(),
+			jen.Comment("parseSlice parses comma separated items, empty value is an empty slice."),
+			jen.Line(),
+			jen.Func().Id("parseSlice").
+				Types(jen.Id("T").Any()).
+				Params(jen.Id("parse").Add(parser.Clone())).
+				Func().Params(jen.String()).Params(jen.Index().Id("T"), jen.Error()).
+				Block(
+					jen.Return(jen.Func().Params(jen.Id("value").String()).Params(jen.Index().Id("T"), jen.Error()).Block(
+						jen.If(jen.Qual("strings", "TrimSpace").Call(jen.Id("value")).Op("==").Lit("")).Block(
+							jen.Return(jen.Nil(), jen.Nil()),
+						),
+						jen.Id("items").Op(":=").Qual("strings", "Split").Call(jen.Id("value"), jen.Lit(",")),
+						jen.Id("values").Op(":=").Make(jen.Index().Id("T"), jen.Lit(0), jen.Len(jen.Id("items"))),
+						jen.For(jen.List(jen.Id("i"), jen.Id("item")).Op(":=").Range().Id("items")).Block(
+							jen.List(jen.Id("v"), jen.Err()).Op(":=").Id("parse").Call(
+								jen.Qual("strings", "TrimSpace").Call(jen.Id("item")),
+							),
+							jen.If(jen.Err().Op("!=").Nil()).Block(
+								jen.Return(jen.Nil(), g.params.wrapFormattedError("item %d", []jen.Code{jen.Id("i")}, jen.Err())),
+							),
+							jen.Id("values").Op("=").Append(jen.Id("values"), jen.Id("v")),
+						),
+						jen.Line(),
+						jen.Return(jen.Id("values"), jen.Nil()),
+					)),
+				),
+		)
+	}
+}
+
+// generateBindField generates a helper reading the field of the source service in explicit errors mode.
+func (g *InternalContainerGenerator) generateBindField() {
+	g.file.Add(
+		jen.Line(),
+		jen.Comment("bindField reads the field of the source service, the error of the source service is returned as is."),
+		jen.Line(),
+		jen.Func().Id("bindField").
+			Types(jen.List(jen.Id("S"), jen.Id("T")).Any()).
+			Params(jen.Id("field").Func().Params(jen.Id("S")).Id("T")).
+			Func().Params(jen.Id("S"), jen.Error()).Params(jen.Id("T"), jen.Error()).
+			Block(
+				jen.Return(jen.Func().Params(jen.Id("s").Id("S"), jen.Err().Error()).Params(jen.Id("T"), jen.Error()).Block(
+					jen.If(jen.Err().Op("!=").Nil()).Block(
+						jen.Var().Id("zero").Id("T"),
+						jen.Return(jen.Id("zero"), jen.Err()),
+					),
+					jen.Line(),
+					jen.Return(jen.Id("field").Call(jen.Id("s")), jen.Nil()),
+				)),
+			),
+	)
+}
+
+// envParser returns the generated parser of the service type.
+func envParser(definition TypeDefinition) jen.Code {
+	if definition.IsSlice {
+		definition.IsSlice = false
+
+		return jen.Id("parseSlice").Call(jen.Id(envParserName(definition)))
+	}
+
+	return jen.Id(envParserName(definition))
+}
+
+func envParserName(definition TypeDefinition) string {
+	switch {
+	case definition.IsURL():
+		return "parseURL"
+	case definition.IsDuration():
+		return "parseDuration"
+	}
+
+	return "parse" + strings.Title(definition.Name)
+}
+
+// generateEnvParser generates the parser of the basic type, duration or URL.
+func generateEnvParser(name string, definition TypeDefinition) *jen.Statement {
+	var result *jen.Statement
+	var body []jen.Code
+	value := jen.Id("value")
+
+	switch {
+	case definition.IsURL():
+		result = jen.Op("*").Qual("net/url", "URL")
+		body = []jen.Code{jen.Return(jen.Qual("net/url", "Parse").Call(value))}
+	case definition.IsDuration():
+		result = jen.Qual("time", "Duration")
+		body = []jen.Code{jen.Return(jen.Qual("time", "ParseDuration").Call(value))}
+	case definition.Name == "string":
+		result = jen.String()
+		body = []jen.Code{jen.Return(value, jen.Nil())}
+	case definition.Name == "bool":
+		result = jen.Bool()
+		body = []jen.Code{jen.Return(jen.Qual("strconv", "ParseBool").Call(value))}
+	default:
+		result = jen.Id(definition.Name)
+		var parse *jen.Statement
+		switch {
+		case strings.HasPrefix(definition.Name, "uint"):
+			parse = jen.Qual("strconv", "ParseUint").Call(value, jen.Lit(10), jen.Lit(bitSize(definition.Name)))
+		case strings.HasPrefix(definition.Name, "int"):
+			parse = jen.Qual("strconv", "ParseInt").Call(value, jen.Lit(10), jen.Lit(bitSize(definition.Name)))
+		default:
+			parse = jen.Qual("strconv", "ParseFloat").Call(value, jen.Lit(bitSize(definition.Name)))
+		}
+		if bitSize(definition.Name) == 64 {
+			body = []jen.Code{jen.Return(parse)}
+		} else {
+			body = []jen.Code{
+				jen.List(jen.Id("v"), jen.Err()).Op(":=").Add(parse),
+				jen.Line(),
+				jen.Return(jen.Id(definition.Name).Call(jen.Id("v")), jen.Err()),
+			}
+		}
+	}
+
+	return jen.Func().Id(name).
+		Params(jen.Id("value").String()).
+		Params(result, jen.Error()).
+		Block(body...)
+}
+
+func (g *InternalContainerGenerator) serviceField(service *ServiceDefinition, container *ContainerDefinition) *jen.Statement {
+	field := jen.Id("c")
+	if container != nil {
+		field = field.Dot(strcase.ToLowerCamel(container.Name))
+	}
+
+	return field.Dot(strcase.ToLowerCamel(service.Name))
+}
+
+func (g *InternalContainerGenerator) withContainerDeferredLock() []jen.Code {
+	if !g.isConcurrent() {
+		return nil
+	}
+
+	return []jen.Code{
+		jen.Id("c").Dot("mu").Dot("Lock").Call(),
+		jen.Defer().Id("c").Dot("mu").Dot("Unlock").Call(),
+		jen.Line(),
+	}
+}
+
+// observesBuilds reports whether the construction of services is timed for hooks or for the description.
+func (g *InternalContainerGenerator) observesBuilds() bool {
+	return g.params.Hooks || g.params.Describe
+}
+
+func (g *InternalContainerGenerator) hasNamedServices() bool {
+	return g.container.HasService(func(service *ServiceDefinition) bool {
+		return service.IsNamed
+	})
+}
+
+func (g *InternalContainerGenerator) isConcurrent() bool {
+	return g.params.Concurrency == ServiceConcurrency
+}
+
+func (g *InternalContainerGenerator) getterResults(service *ServiceDefinition) *jen.Statement {
+	if g.isExplicit() {
+		return jen.Params(jen.Do(g.container.Type(service.Type)), jen.Error())
+	}
+
+	return jen.Do(g.container.Type(service.Type))
+}
+
+func (g *InternalContainerGenerator) isExplicit() bool {
+	return g.params.ErrorHandling.Explicit
+}
+
+// hasErrorList reports whether initialization errors are accumulated by the container.
+func (g *InternalContainerGenerator) hasErrorList() bool {
+	return !g.isExplicit() && !g.isIsolated()
+}
+
+func (g *InternalContainerGenerator) isIsolated() bool {
+	return g.params.ErrorHandling.Policy == IsolateErrorPolicy
+}
+
+func (g *InternalContainerGenerator) hasRetryBackoff() bool {
+	return g.isIsolated() && g.params.ErrorHandling.RetryBackoff > 0
+}
+
+func (g *InternalContainerGenerator) bitsetType() string {
+	if g.isConcurrent() {
+		return "syncBitset"
+	}
+
+	return "bitset"
+}
+
+func (g *InternalContainerGenerator) hasClosers() bool {
+	return g.container.HasService(func(service *ServiceDefinition) bool {
+		return service.HasCloser
+	})
+}
+
+func (g *InternalContainerGenerator) hasRunners() bool {
+	return g.container.HasService(func(service *ServiceDefinition) bool {
+		return service.IsRunnable()
+	})
+}
+
+func (g *InternalContainerGenerator) addErrorHandlingMethods() *jen.Statement {
+	return g.file.Add(
+		jen.Line(),
+		jen.Commentf("Error returns the first initialization error, which can be set via SetError in a service definition."),
+		jen.Line(),
+		jen.Func().
+			Params(jen.Id("c").Op("*").Id("Container")).
+			Id("Error").Params().Error().
+			Block(slices.Concat(
+				g.withContainerDeferredLock(),
+				[]jen.Code{jen.Return(g.params.joinErrors(jen.Id("c").Dot("errs").Op("...")))},
+			)...),
+		jen.Line(),
+		jen.Commentf("SetError sets the first error into container. The error is used in the public container to return an initialization error."),
+		jen.Line(),
+		jen.Commentf("Deprecated. Return error in factory instead."),
+		jen.Line(),
+		jen.Func().
+			Params(jen.Id("c").Op("*").Id("Container")).
+			Id("SetError").Params(jen.Err().Error()).
+			Block(
+				jen.Id("c").Dot("addError").Call(jen.Err()),
+			),
 		jen.Line(),
 		jen.Line(),
 		jen.Func().
@@ -313,8 +1732,317 @@ func (g *InternalContainerGenerator) addErrorHandlingMethods() *jen.Statement {
 			Id("addError").Params(jen.Err().Error()).
 			Block(
 				jen.If(jen.Err().Op("!=").Nil()).Block(
-					jen.Id("c").Dot("errs").Op("=").Append(jen.Id("c").Dot("errs"), jen.Err()),
+					g.withContainerLock(
+						jen.Id("c").Dot("errs").Op("=").Append(jen.Id("c").Dot("errs"), jen.Err()),
+					)...,
 				),
 			),
 	)
 }
+
+// addErrorIsolationMethods generates the error handling for the isolate error policy.
+// Initialization errors are collected by InitErrors carried by the context: the public
+// container creates it for every call and every service initialization creates its own
+// one to detect failures of its dependencies.
+func (g *InternalContainerGenerator) addErrorIsolationMethods() {
+	g.file.Add(
+		jen.Line(),
+		jen.Comment("InitErrors collects errors of the service initialization and its dependencies."),
+		jen.Line(),
+		jen.Type().Id("InitErrors").Struct(
+			jen.Id("mu").Qual("sync", "Mutex"),
+			jen.Id("errs").Index().Error(),
+		),
+		jen.Line(),
+		jen.Line(),
+		jen.Type().Id("initErrorsKey").Struct(),
+		jen.Line(),
+		jen.Line(),
+		jen.Comment("WithInitErrors returns the context that collects initialization errors of the requested services."),
+		jen.Line(),
+		jen.Func().Id("WithInitErrors").
+			Params(jen.Id("ctx").Qual("context", "Context")).
+			Params(jen.Qual("context", "Context"), jen.Op("*").Id("InitErrors")).
+			Block(
+				jen.Id("errs").Op(":=").Op("&").Id("InitErrors").Values(),
+				jen.Line(),
+				jen.Return(
+					jen.Qual("context", "WithValue").Call(jen.Id("ctx"), jen.Id("initErrorsKey").Values(), jen.Id("errs")),
+					jen.Id("errs"),
+				),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Comment("Err returns the joined initialization errors."),
+		jen.Line(),
+		jen.Func().Params(jen.Id("e").Op("*").Id("InitErrors")).
+			Id("Err").Params().Error().
+			Block(
+				jen.Id("e").Dot("mu").Dot("Lock").Call(),
+				jen.Defer().Id("e").Dot("mu").Dot("Unlock").Call(),
+				jen.Line(),
+				jen.Return(g.params.joinErrors(jen.Id("e").Dot("errs").Op("..."))),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Params(jen.Id("e").Op("*").Id("InitErrors")).
+			Id("add").Params(jen.Err().Error()).
+			Block(
+				jen.Id("e").Dot("mu").Dot("Lock").Call(),
+				jen.Id("e").Dot("errs").Op("=").Append(jen.Id("e").Dot("errs"), jen.Err()),
+				jen.Id("e").Dot("mu").Dot("Unlock").Call(),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Comment("reportError adds the error to the initialization errors collected by the context."),
+		jen.Line(),
+		jen.Func().Id("reportError").
+			Params(jen.Id("ctx").Qual("context", "Context"), jen.Err().Error()).
+			Block(
+				jen.If(
+					jen.List(jen.Id("errs"), jen.Id("ok")).Op(":=").Id("ctx").Dot("Value").Call(jen.Id("initErrorsKey").Values()).
+						Assert(jen.Op("*").Id("InitErrors")),
+					jen.Id("ok"),
+				).Block(
+					jen.Id("errs").Dot("add").Call(jen.Err()),
+				),
+			),
+	)
+
+	if !g.hasRetryBackoff() {
+		return
+	}
+
+	g.file.Add(
+		jen.Line(),
+		jen.Const().Id("retryBackoff").Op("=").Add(durationLiteral(g.params.ErrorHandling.RetryBackoff)),
+		jen.Line(),
+		jen.Line(),
+		jen.Type().Id("failure").Struct(
+			jen.Id("err").Error(),
+			jen.Id("retryAt").Qual("time", "Time"),
+		),
+		jen.Line(),
+		jen.Line(),
+		jen.Comment("canInit checks that the initialization of the failed service can be retried."),
+		jen.Line(),
+		jen.Comment("Otherwise, the last error of the service is reported."),
+		jen.Line(),
+		jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
+			Id("canInit").
+			Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("id").Int()).
+			Bool().
+			Block(
+				jen.Id("f").Op(":=").Id("c").Dot("failures").Index(jen.Id("id")),
+				jen.If(
+					jen.Id("f").Dot("err").Op("==").Nil().
+						Op("||").
+						Op("!").Qual("time", "Now").Call().Dot("Before").Call(jen.Id("f").Dot("retryAt")),
+				).Block(
+					jen.Return(jen.True()),
+				),
+				jen.Line(),
+				jen.Id("reportError").Call(jen.Id("ctx"), jen.Id("f").Dot("err")),
+				jen.Line(),
+				jen.Return(jen.False()),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
+			Id("fail").
+			Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("id").Int(), jen.Err().Error()).
+			Block(
+				jen.Id("c").Dot("failures").Index(jen.Id("id")).Op("=").Id("failure").Values(jen.Dict{
+					jen.Id("err"):     jen.Err(),
+					jen.Id("retryAt"): jen.Qual("time", "Now").Call().Dot("Add").Call(jen.Id("retryBackoff")),
+				}),
+				jen.Id("reportError").Call(jen.Id("ctx"), jen.Err()),
+			),
+	)
+}
+
+// durationLiteral generates the duration expression in the largest unit without loss of precision.
+func durationLiteral(d time.Duration) *jen.Statement {
+	units := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"Hour", time.Hour},
+		{"Minute", time.Minute},
+		{"Second", time.Second},
+		{"Millisecond", time.Millisecond},
+		{"Microsecond", time.Microsecond},
+	}
+	for _, unit := range units {
+		if d%unit.value == 0 {
+			return jen.Lit(int(d/unit.value)).Op("*").Qual("time", unit.name)
+		}
+	}
+
+	return jen.Lit(int(d)).Op("*").Qual("time", "Nanosecond")
+}
+
+// generateDescription generates the static table describing services and the statistics
+// of their construction. The table is combined with the runtime state by Describe method.
+func (g *InternalContainerGenerator) generateDescription() {
+	count := g.container.ServicesCount()
+	infos := make([]jen.Code, 0, count)
+	for _, service := range g.container.Services {
+		infos = append(infos, g.serviceInfo(service))
+	}
+	for _, container := range g.container.Containers {
+		for _, service := range container.Services {
+			infos = append(infos, g.serviceInfo(service))
+		}
+	}
+	infos = append(infos, jen.Line())
+
+	g.file.Add(
+		jen.Line(),
+		jen.Comment("ServiceInfo describes the service of the container and its runtime state."),
+		jen.Line(),
+		jen.Type().Id("ServiceInfo").Struct(
+			jen.Comment("ID is the name of the service qualified by the name of attached container."),
+			jen.Id("ID").String().Tag(map[string]string{"json": "id"}),
+			jen.Id("Path").Index().String().Tag(map[string]string{"json": "path"}),
+			jen.Id("Type").String().Tag(map[string]string{"json": "type"}),
+			jen.Id("Factory").String().Tag(map[string]string{"json": "factory,omitempty"}),
+			jen.Id("Env").String().Tag(map[string]string{"json": "env,omitempty"}),
+			jen.Id("Value").String().Tag(map[string]string{"json": "value,omitempty"}),
+			jen.Id("From").String().Tag(map[string]string{"json": "from,omitempty"}),
+			jen.Id("CloseMethod").String().Tag(map[string]string{"json": "closeMethod,omitempty"}),
+			jen.Id("StartMethod").String().Tag(map[string]string{"json": "startMethod,omitempty"}),
+			jen.Id("StopMethod").String().Tag(map[string]string{"json": "stopMethod,omitempty"}),
+			jen.Id("Public").Bool().Tag(map[string]string{"json": "public"}),
+			jen.Id("Required").Bool().Tag(map[string]string{"json": "required"}),
+			jen.Id("Settable").Bool().Tag(map[string]string{"json": "settable"}),
+			jen.Line(),
+			jen.Id("Initialized").Bool().Tag(map[string]string{"json": "initialized"}),
+			jen.Comment("Err is the error of the last failed construction of the service."),
+			jen.Id("Err").Error().Tag(map[string]string{"json": "-"}),
+			jen.Id("Error").String().Tag(map[string]string{"json": "error,omitempty"}),
+			jen.Comment("Duration is the construction time of the service including its dependencies."),
+			jen.Id("Duration").Qual("time", "Duration").Tag(map[string]string{"json": "duration,omitempty"}),
+			jen.Comment("Order is the number of the service built by the factory in order of initialization, starting from 1."),
+			jen.Id("Order").Int().Tag(map[string]string{"json": "order,omitempty"}),
+		),
+		jen.Line(),
+		jen.Line(),
+		jen.Var().Id("serviceInfos").Op("=").Index(jen.Lit(count)).Id("ServiceInfo").Values(infos...),
+		jen.Line(),
+		jen.Line(),
+		jen.Type().Id("buildState").Struct(
+			jen.Err().Error(),
+			jen.Id("duration").Qual("time", "Duration"),
+			jen.Id("order").Int(),
+		),
+		jen.Line(),
+		jen.Line(),
+		jen.Comment("buildStats records construction of services for the description."),
+		jen.Line(),
+		jen.Type().Id("buildStats").Struct(
+			jen.Id("mu").Qual("sync", "Mutex"),
+			jen.Id("count").Int(),
+			jen.Id("states").Index(jen.Lit(count)).Id("buildState"),
+		),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Params(jen.Id("s").Op("*").Id("buildStats")).
+			Id("record").
+			Params(jen.Id("id").Int(), jen.Id("started").Qual("time", "Time"), jen.Err().Error()).
+			Block(
+				jen.Id("s").Dot("mu").Dot("Lock").Call(),
+				jen.Defer().Id("s").Dot("mu").Dot("Unlock").Call(),
+				jen.Line(),
+				jen.Id("state").Op(":=").Op("&").Id("s").Dot("states").Index(jen.Id("id")),
+				jen.Id("state").Dot("err").Op("=").Err(),
+				jen.Id("state").Dot("duration").Op("=").Qual("time", "Since").Call(jen.Id("started")),
+				jen.If(jen.Err().Op("==").Nil()).Block(
+					jen.Id("s").Dot("count").Op("++"),
+					jen.Id("state").Dot("order").Op("=").Id("s").Dot("count"),
+				),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Comment("Describe returns the description of services with their runtime state."),
+		jen.Line(),
+		jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
+			Id("Describe").
+			Params().
+			Index().Id("ServiceInfo").
+			Block(
+				jen.Id("c").Dot("builds").Dot("mu").Dot("Lock").Call(),
+				jen.Defer().Id("c").Dot("builds").Dot("mu").Dot("Unlock").Call(),
+				jen.Line(),
+				jen.Id("infos").Op(":=").Make(jen.Index().Id("ServiceInfo"), jen.Len(jen.Id("serviceInfos"))),
+				jen.For(jen.List(jen.Id("id"), jen.Id("info")).Op(":=").Range().Id("serviceInfos")).Block(
+					jen.Id("state").Op(":=").Id("c").Dot("builds").Dot("states").Index(jen.Id("id")),
+					jen.Id("info").Dot("Path").Op("=").Qual("slices", "Clone").Call(jen.Id("info").Dot("Path")),
+					jen.Id("info").Dot("Initialized").Op("=").Id("c").Dot("init").Dot("IsSet").Call(jen.Id("id")),
+					jen.Id("info").Dot("Err").Op("=").Id("state").Dot("err"),
+					jen.If(jen.Id("state").Dot("err").Op("!=").Nil()).Block(
+						jen.Id("info").Dot("Error").Op("=").Id("state").Dot("err").Dot("Error").Call(),
+					),
+					jen.Id("info").Dot("Duration").Op("=").Id("state").Dot("duration"),
+					jen.Id("info").Dot("Order").Op("=").Id("state").Dot("order"),
+					jen.Id("infos").Index(jen.Id("id")).Op("=").Id("info"),
+				),
+				jen.Line(),
+				jen.Return(jen.Id("infos")),
+			),
+	)
+}
+
+// serviceInfo generates the static description of the service.
+func (g *InternalContainerGenerator) serviceInfo(service *ServiceDefinition) jen.Code {
+	path := make([]jen.Code, 0, 2)
+	if service.Prefix != "" {
+		path = append(path, jen.Lit(service.Prefix))
+	}
+	path = append(path, jen.Lit(service.Title()))
+
+	field := func(name string, value jen.Code) jen.Code {
+		return jen.Id(name).Op(":").Add(value)
+	}
+	fields := []jen.Code{
+		field("ID", jen.Lit(service.FullName())),
+		field("Path", jen.Index().String().Values(path...)),
+		field("Type", jen.Lit(service.Type.String())),
+	}
+	if service.HasFactory() {
+		factory := g.factoryPackage(service) + "." + g.params.Factories.Name(service)
+		if g.params.isFactoryMethod(service) {
+			factory = g.factoryPackage(service) + ".(*" + g.params.Factories.Struct + ")." + g.params.Factories.Name(service)
+		}
+		fields = append(fields, field("Factory", jen.Lit(factory)))
+	}
+	if service.EnvVar != "" {
+		fields = append(fields, field("Env", jen.Lit(service.EnvVar)))
+	}
+	if service.HasValue {
+		fields = append(fields, field("Value", jen.Lit(service.Value)))
+	}
+	if service.From != "" {
+		fields = append(fields, field("From", jen.Lit(service.From)))
+	}
+	if service.HasCloser {
+		fields = append(fields, field("CloseMethod", jen.Lit(service.CloseMethod)))
+	}
+	if service.StartMethod != "" {
+		fields = append(fields, field("StartMethod", jen.Lit(service.StartMethod)))
+	}
+	if service.StopMethod != "" {
+		fields = append(fields, field("StopMethod", jen.Lit(service.StopMethod)))
+	}
+	if service.IsPublic {
+		fields = append(fields, field("Public", jen.True()))
+	}
+	if service.IsRequired {
+		fields = append(fields, field("Required", jen.True()))
+	}
+	if service.HasSetter {
+		fields = append(fields, field("Settable", jen.True()))
+	}
+
+	return jen.Line().Id(service.ID()).Op(":").Values(fields...)
+}
@@ -0,0 +1,102 @@
+package di
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// validateLiteral checks that the literal from the service definition is parsed
+// into the value of the type the same way as by the generated parsers.
+func validateLiteral(definition TypeDefinition, literal string) error {
+	if definition.IsSlice {
+		for _, item := range splitList(literal) {
+			if err := validateLiteral(TypeDefinition{Name: definition.Name}, item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	var err error
+	switch {
+	case definition.IsURL():
+		_, err = url.Parse(literal)
+	case definition.IsDuration():
+		_, err = time.ParseDuration(literal)
+	case definition.Name == "bool":
+		_, err = strconv.ParseBool(literal)
+	case strings.HasPrefix(definition.Name, "uint"):
+		_, err = strconv.ParseUint(literal, 10, bitSize(definition.Name))
+	case strings.HasPrefix(definition.Name, "int"):
+		_, err = strconv.ParseInt(literal, 10, bitSize(definition.Name))
+	case strings.HasPrefix(definition.Name, "float"):
+		_, err = strconv.ParseFloat(literal, bitSize(definition.Name))
+	}
+
+	return err
+}
+
+// valueLiteral generates the literal of the service value and reports whether it is parsed with an error.
+// The value is validated by the parser, so only URLs are parsed at runtime.
+func valueLiteral(definition TypeDefinition, value string) (*jen.Statement, bool) {
+	switch {
+	case definition.IsURL():
+		return jen.Qual("net/url", "Parse").Call(jen.Lit(value)), true
+	case definition.IsDuration():
+		d, _ := time.ParseDuration(value)
+
+		return durationLiteral(d), false
+	case definition.Name == "string":
+		return jen.Lit(value), false
+	case definition.Name == "bool":
+		b, _ := strconv.ParseBool(value)
+
+		return jen.Lit(b), false
+	case strings.HasPrefix(definition.Name, "uint"):
+		v, _ := strconv.ParseUint(value, 10, bitSize(definition.Name))
+
+		return jen.Op(strconv.FormatUint(v, 10)), false
+	case strings.HasPrefix(definition.Name, "int"):
+		v, _ := strconv.ParseInt(value, 10, bitSize(definition.Name))
+
+		return jen.Op(strconv.FormatInt(v, 10)), false
+	}
+	v, _ := strconv.ParseFloat(value, bitSize(definition.Name))
+
+	return jen.Op(strconv.FormatFloat(v, 'g', -1, bitSize(definition.Name))), false
+}
+
+// tagListSeparator separates items of slice values in options of the tag,
+// because commas separate the options themselves.
+const tagListSeparator = ";"
+
+// listFromTag converts items of the slice value from the option of the tag
+// into the comma separated list, see splitList.
+func listFromTag(value string) string {
+	return strings.ReplaceAll(value, tagListSeparator, ",")
+}
+
+// splitList splits comma separated items of the slice value, empty string is an empty slice.
+func splitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	items := strings.Split(s, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+
+	return items
+}
+
+// bitSize returns the size of the numeric type by its name, zero is for int and uint.
+func bitSize(typeName string) int {
+	size, _ := strconv.Atoi(strings.TrimLeft(typeName, "intufloa"))
+
+	return size
+}
@@ -23,8 +23,8 @@ func NewLookupContainerGenerator(
 }
 
 func (g *LookupContainerGenerator) Generate() (*File, error) {
-	file := NewFileBuilder(g.fileLocator.GetPackageFilePath(LookupPackage, "container.go"), "lookup")
-	file.AddHeading(g.params.Version)
+	file := NewFileBuilder(g.fileLocator.GetPackageFilePath(LookupPackage, "container.go"), g.params.Layout.Lookup.Name)
+	file.AddHeading(g.params)
 
 	file.AddImportAliases(g.container.Imports)
 	file.Add(g.generateRootContainerInterface())
@@ -39,17 +39,20 @@ func (g *LookupContainerGenerator) Generate() (*File, error) {
 
 func (g *LookupContainerGenerator) generateRootContainerInterface() *jen.Statement {
 	methods := make([]jen.Code, 0, len(g.container.Services)+len(g.container.Containers)+3)
-	methods = append(methods,
-		jen.Commentf("SetError sets the first error into container. The error is used in the public container to return an initialization error."),
-		jen.Commentf("Deprecated. Return error in factory instead."),
-		jen.Id("SetError").Params(jen.Id("err").Error()),
-		jen.Line(),
-	)
+	// errors of the isolate policy are bound to services, so they cannot be set for the whole container
+	if g.params.ErrorHandling.Policy != IsolateErrorPolicy && !g.params.ErrorHandling.Explicit {
+		methods = append(methods,
+			jen.Commentf("SetError sets the first error into container. The error is used in the public container to return an initialization error."),
+			jen.Commentf("Deprecated. Return error in factory instead."),
+			jen.Id("SetError").Params(jen.Id("err").Error()),
+			jen.Line(),
+		)
+	}
 
 	for _, service := range g.container.Services {
 		methods = append(methods, jen.Id(service.Title()).
 			Params(jen.Id("ctx").Qual("context", "Context")).
-			Do(g.container.Type(service.Type)),
+			Add(g.getterResults(service)),
 		)
 	}
 
@@ -69,9 +72,159 @@ func (g *LookupContainerGenerator) generateContainerInterface(container *Contain
 	for _, service := range container.Services {
 		methods = append(methods, jen.Id(service.Title()).
 			Params(jen.Id("ctx").Qual("context", "Context")).
-			Do(g.container.Type(service.Type)),
+			Add(g.getterResults(service)),
 		)
 	}
 
 	return jen.Type().Id(container.Type.Name).Interface(methods...)
 }
+
+// GenerateFakes generates lookuptest package with fakes of lookup containers for unit tests of factories.
+// Services of fakes are set up by functions, access to the service without the function panics.
+func (g *LookupContainerGenerator) GenerateFakes() (*File, error) {
+	file := NewFileBuilder(g.fileLocator.GetPackageFilePath(LookupTestPackage, "container.go"), g.params.Layout.LookupTest.Name)
+	file.AddHeading(g.params)
+	file.AddImportAliases(g.container.Imports)
+
+	file.Add(g.generateFakeHelpers()...)
+	file.Add(jen.Line(), jen.Line())
+	file.Add(g.generateFake("Container", g.container.Services, g.container.Containers)...)
+	for _, attachedContainer := range g.container.Containers {
+		file.Add(jen.Line(), jen.Line())
+		file.Add(g.generateFake(attachedContainer.Type.Name, attachedContainer.Services, nil)...)
+	}
+
+	return file.GetFile()
+}
+
+func (g *LookupContainerGenerator) generateFakeHelpers() []jen.Code {
+	results := jen.Id("T")
+	valueResults := jen.Id("s")
+	if g.params.ErrorHandling.Explicit {
+		results = jen.Params(jen.Id("T"), jen.Error())
+		valueResults = jen.List(jen.Id("s"), jen.Nil())
+	}
+
+	helpers := []jen.Code{
+		jen.Comment("Value returns the function returning the service, it is used to set up services of fakes."),
+		jen.Line(),
+		jen.Func().Id("Value").Types(jen.Id("T").Any()).
+			Params(jen.Id("s").Id("T")).
+			Func().Params(jen.Qual("context", "Context")).Add(results.Clone()).
+			Block(
+				jen.Return(jen.Func().Params(jen.Qual("context", "Context")).Add(results.Clone()).Block(
+					jen.Return(valueResults),
+				)),
+			),
+	}
+	if g.params.ErrorHandling.Explicit {
+		helpers = append(helpers,
+			jen.Line(),
+			jen.Line(),
+			jen.Comment("Error returns the function failing with the error, it is used to set up failed services of fakes."),
+			jen.Line(),
+			jen.Func().Id("Error").Types(jen.Id("T").Any()).
+				Params(jen.Err().Error()).
+				Func().Params(jen.Qual("context", "Context")).Add(results.Clone()).
+				Block(
+					jen.Return(jen.Func().Params(jen.Qual("context", "Context")).Add(results.Clone()).Block(
+						jen.Var().Id("s").Id("T"),
+						jen.Line(),
+						jen.Return(jen.Id("s"), jen.Err()),
+					)),
+				),
+		)
+	}
+
+	return helpers
+}
+
+func (g *LookupContainerGenerator) generateFake(
+	name string,
+	services []*ServiceDefinition,
+	containers []*ContainerDefinition,
+) []jen.Code {
+	fields := make([]jen.Code, 0, len(services)+len(containers)+2)
+	methods := make([]jen.Code, 0, 2*(len(services)+len(containers))+2)
+	receiver := jen.Id("c").Op("*").Id(name)
+
+	for _, service := range services {
+		funcName := service.Title() + "Func"
+		fields = append(fields,
+			jen.Id(funcName).Func().Params(jen.Id("ctx").Qual("context", "Context")).Add(g.getterResults(service)),
+		)
+		methods = append(methods,
+			jen.Line(),
+			jen.Line(),
+			jen.Func().Params(receiver.Clone()).
+				Id(service.Title()).
+				Params(jen.Id("ctx").Qual("context", "Context")).
+				Add(g.getterResults(service)).
+				Block(
+					jen.If(jen.Id("c").Dot(funcName).Op("==").Nil()).Block(
+						jen.Panic(jen.Lit("lookuptest: service "+service.FullName()+" is not set")),
+					),
+					jen.Line(),
+					jen.Return(jen.Id("c").Dot(funcName).Call(jen.Id("ctx"))),
+				),
+		)
+	}
+	if len(containers) > 0 {
+		fields = append(fields, jen.Line())
+	}
+	for _, container := range containers {
+		fieldName := container.Title() + "Container"
+		fields = append(fields, jen.Id(fieldName).Id(container.Type.Name))
+		methods = append(methods,
+			jen.Line(),
+			jen.Line(),
+			jen.Func().Params(receiver.Clone()).
+				Id(container.Title()).
+				Params().
+				Qual(g.params.packageName(LookupPackage), container.Type.Name).
+				Block(
+					jen.Return(jen.Op("&").Id("c").Dot(fieldName)),
+				),
+		)
+	}
+	if name == "Container" && g.params.ErrorHandling.Policy != IsolateErrorPolicy && !g.params.ErrorHandling.Explicit {
+		fields = append(fields,
+			jen.Line(),
+			jen.Comment("Errors contains errors set by SetError method."),
+			jen.Id("Errors").Index().Error(),
+		)
+		methods = append(methods,
+			jen.Line(),
+			jen.Line(),
+			jen.Func().Params(receiver.Clone()).
+				Id("SetError").
+				Params(jen.Err().Error()).
+				Block(
+					jen.Id("c").Dot("Errors").Op("=").Append(jen.Id("c").Dot("Errors"), jen.Err()),
+				),
+		)
+	}
+
+	return append(
+		[]jen.Code{
+			jen.Commentf("%s is the fake of lookup.%s. Services are set up by functions,", name, name),
+			jen.Line(),
+			jen.Comment("access to the service without the function panics with the name of the service."),
+			jen.Line(),
+			jen.Type().Id(name).Struct(fields...),
+			jen.Line(),
+			jen.Line(),
+			jen.Var().Id("_").Qual(g.params.packageName(LookupPackage), name).Op("=").
+				Parens(jen.Op("*").Id(name)).Parens(jen.Nil()),
+		},
+		methods...,
+	)
+}
+
+func (g *LookupContainerGenerator) getterResults(service *ServiceDefinition) *jen.Statement {
+	if g.params.ErrorHandling.Explicit {
+		return jen.Params(jen.Do(g.container.Type(service.Type)), jen.Error())
+	}
+
+	return jen.Do(g.container.Type(service.Type))
+}
@@ -0,0 +1,125 @@
+package di
+
+import (
+	"go/token"
+	"path"
+	"strings"
+
+	"github.com/muonsoft/errors"
+)
+
+var packageDirs = [lastPackage]string{
+	InternalPackage:    "internal",
+	DefinitionsPackage: "internal/definitions",
+	FactoriesPackage:   "internal/factories",
+	LookupPackage:      "lookup",
+	TestingPackage:     "ditest",
+	LookupTestPackage:  "lookup/lookuptest",
+}
+
+var packageKinds = [lastPackage]string{
+	PublicPackage:      "public",
+	InternalPackage:    "internal",
+	DefinitionsPackage: "definitions",
+	FactoriesPackage:   "factories",
+	LookupPackage:      "lookup",
+	TestingPackage:     "testing",
+	LookupTestPackage:  "lookup test",
+}
+
+// PackageLayout defines directories and names of the generated packages. Directories are relative
+// to the container dir, the public package is placed into the container dir itself.
+// By default, the name of the package is the last element of its directory.
+type PackageLayout struct {
+	Public      PackageOptions
+	Internal    PackageOptions
+	Definitions PackageOptions
+	Factories   PackageOptions
+	Lookup      PackageOptions
+	Testing     PackageOptions
+	LookupTest  PackageOptions
+}
+
+type PackageOptions struct {
+	Dir  string
+	Name string
+}
+
+func (l PackageLayout) Defaults() PackageLayout {
+	for packageType := PublicPackage + 1; packageType < lastPackage; packageType++ {
+		options := l.options(packageType)
+		if options.Dir == "" {
+			options.Dir = packageDirs[packageType]
+		}
+		options.Dir = strings.Trim(options.Dir, "/")
+		if options.Name == "" {
+			options.Name = path.Base(options.Dir)
+		}
+	}
+
+	return l
+}
+
+// Validate checks that packages have valid names and do not collide with each other.
+func (l PackageLayout) Validate() error {
+	if l.Public.Dir != "" {
+		return errors.Errorf("%w: public package is placed into the container dir", ErrInvalidLayout)
+	}
+	if l.Public.Name != "" && !isPackageName(l.Public.Name) {
+		return errors.Errorf("%w: invalid name %q of public package", ErrInvalidLayout, l.Public.Name)
+	}
+
+	packages := make(map[string]PackageType, lastPackage)
+	for packageType := PublicPackage + 1; packageType < lastPackage; packageType++ {
+		options := l.Package(packageType)
+		if !isPackageName(options.Name) {
+			return errors.Errorf("%w: invalid name %q of %s package", ErrInvalidLayout, options.Name, packageKinds[packageType])
+		}
+		if options.Dir == "." || path.Clean(options.Dir) != options.Dir || strings.HasPrefix(options.Dir, "..") {
+			return errors.Errorf("%w: invalid dir %q of %s package", ErrInvalidLayout, options.Dir, packageKinds[packageType])
+		}
+		if other, exists := packages[options.Dir]; exists {
+			return errors.Errorf(
+				"%w: %s package collides with %s package in dir %q",
+				ErrInvalidLayout, packageKinds[packageType], packageKinds[other], options.Dir,
+			)
+		}
+		packages[options.Dir] = packageType
+	}
+
+	return nil
+}
+
+// Package returns options of the package, the dir of the public package is empty.
+func (l PackageLayout) Package(packageType PackageType) PackageOptions {
+	if options := l.options(packageType); options != nil {
+		return *options
+	}
+
+	return PackageOptions{}
+}
+
+func (l *PackageLayout) options(packageType PackageType) *PackageOptions {
+	switch packageType {
+	case PublicPackage:
+		return &l.Public
+	case InternalPackage:
+		return &l.Internal
+	case DefinitionsPackage:
+		return &l.Definitions
+	case FactoriesPackage:
+		return &l.Factories
+	case LookupPackage:
+		return &l.Lookup
+	case TestingPackage:
+		return &l.Testing
+	case LookupTestPackage:
+		return &l.LookupTest
+	}
+
+	return nil
+}
+
+func isPackageName(name string) bool {
+	return token.IsIdentifier(name) && name != "_"
+}
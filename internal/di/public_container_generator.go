@@ -30,24 +30,82 @@ func NewPublicContainerGenerator(
 }
 
 func (g *PublicContainerGenerator) Generate() (*File, error) {
-	g.file.AddHeading(g.params.Version)
+	g.file.AddHeading(g.params)
 	g.file.AddImportAliases(g.container.Imports)
 
+	fields := make([]jen.Code, 0, 2)
+	if !g.isConcurrent() {
+		fields = append(fields, jen.Id("mu").Op("*").Qual("sync", "Mutex"))
+	}
+	fields = append(fields, jen.Id("c").Op("*").Qual(g.params.packageName(InternalPackage), "Container"))
+
 	g.file.Add(
-		jen.Type().Id("Container").Struct(
-			jen.Id("mu").Op("*").Qual("sync", "Mutex"),
-			jen.Id("c").Op("*").Qual(g.params.packageName(InternalPackage), "Container"),
-		),
+		jen.Type().Id("Container").Struct(fields...),
 		jen.Line(),
 		jen.Line(),
 		jen.Type().Id("Injector").
 			Func().Params(jen.Id("c").Op("*").Id("Container")).Error(),
 		jen.Line(),
+		jen.Line(),
+		jen.Comment("ServiceError is the initialization error of the service, it contains the chain of dependencies"),
+		jen.Line(),
+		jen.Comment("from the requested service to the failed one."),
+		jen.Line(),
+		jen.Type().Id("ServiceError").Op("=").Qual(g.params.packageName(InternalPackage), "ServiceError"),
+		jen.Line(),
 	)
+	if g.params.Factories.Struct != "" {
+		g.file.Add(
+			jen.Line(),
+			jen.Commentf("%s creates services of the container, its instance is passed into NewContainer.", g.params.Factories.Struct),
+			jen.Line(),
+			jen.Type().Id(g.params.Factories.Struct).Op("=").Qual(g.params.packageName(FactoriesPackage), g.params.Factories.Struct),
+			jen.Line(),
+		)
+	}
+	if g.params.Hooks {
+		g.file.Add(
+			jen.Line(),
+			jen.Comment("Hooks observes construction of services, see SetHooks."),
+			jen.Line(),
+			jen.Type().Id("Hooks").Op("=").Qual(g.params.packageName(InternalPackage), "Hooks"),
+			jen.Line(),
+		)
+	}
+	if g.hasNamedServices() {
+		g.file.Add(
+			jen.Line(),
+			jen.Comment("ErrServiceNotFound is returned by Get when the service does not exist or is not available by name."),
+			jen.Line(),
+			jen.Var().Id("ErrServiceNotFound").Op("=").Qual(g.params.packageName(InternalPackage), "ErrServiceNotFound"),
+			jen.Line(),
+		)
+	}
+	if g.params.Describe {
+		g.file.Add(
+			jen.Line(),
+			jen.Comment("ServiceInfo describes the service of the container and its runtime state, see Describe."),
+			jen.Line(),
+			jen.Type().Id("ServiceInfo").Op("=").Qual(g.params.packageName(InternalPackage), "ServiceInfo"),
+			jen.Line(),
+		)
+	}
+	if g.params.ErrorHandling.RecoverPanics {
+		g.file.Add(
+			jen.Line(),
+			jen.Comment("PanicError is the panic recovered from the service factory, it contains the stack trace."),
+			jen.Line(),
+			jen.Type().Id("PanicError").Op("=").Qual(g.params.packageName(InternalPackage), "PanicError"),
+			jen.Line(),
+		)
+	}
 
 	methods := make([]jen.Code, 0, 2*len(g.container.Services))
-	arguments := make([]jen.Code, 0, 1)
+	arguments := make([]jen.Code, 0, 2)
 	argumentSetters := make([]jen.Code, 0)
+	if g.params.Factories.Struct != "" {
+		arguments = append(arguments, jen.Id("factories").Op("*").Id(g.params.Factories.Struct))
+	}
 	gettersCount := 0
 
 	for _, service := range g.container.Services {
@@ -89,8 +147,25 @@ func (g *PublicContainerGenerator) Generate() (*File, error) {
 
 	g.file.Add(g.generateConstructor(arguments, argumentSetters))
 	g.file.Add(methods...)
+	if g.params.Hooks {
+		g.file.Add(jen.Line(), jen.Line(), g.generateHooksSetter())
+	}
+	if g.params.TestSupport {
+		g.file.Add(jen.Line(), jen.Line(), g.generateOverride())
+	}
+	hasNamedServices := g.hasNamedServices()
+	if hasNamedServices {
+		g.file.Add(jen.Line(), jen.Line(), g.generateNamedGetter())
+	}
+	if g.params.Describe {
+		g.file.Add(g.generateDescribe()...)
+	}
 	g.file.Add(jen.Line(), g.generateCloser())
-	if gettersCount > 0 {
+	hasRunners := g.hasRunners()
+	if hasRunners {
+		g.file.Add(g.generateRun()...)
+	}
+	if gettersCount > 0 || hasRunners || hasNamedServices {
 		g.file.Add(g.generateErrorHandler()...)
 	}
 
@@ -98,6 +173,17 @@ func (g *PublicContainerGenerator) Generate() (*File, error) {
 }
 
 func (g *PublicContainerGenerator) generateConstructor(arguments []jen.Code, argumentSetters []jen.Code) *jen.Statement {
+	internalArguments := make([]jen.Code, 0, 1)
+	if g.params.Factories.Struct != "" {
+		internalArguments = append(internalArguments, jen.Id("factories"))
+	}
+	values := jen.Dict{
+		jen.Id("c"): jen.Qual(g.params.packageName(InternalPackage), "NewContainer").Call(internalArguments...),
+	}
+	if !g.isConcurrent() {
+		values[jen.Id("mu")] = jen.Op("&").Qual("sync", "Mutex").Op("{}")
+	}
+
 	return jen.Func().Id("NewContainer").
 		Params(arguments...).
 		Params(
@@ -106,10 +192,7 @@ func (g *PublicContainerGenerator) generateConstructor(arguments []jen.Code, arg
 		).
 		Block(slices.Concat(
 			[]jen.Code{
-				jen.Id("c").Op(":=").Op("&").Id("Container").Values(jen.Dict{
-					jen.Id("mu"): jen.Op("&").Qual("sync", "Mutex").Op("{}"),
-					jen.Id("c"):  jen.Qual(g.params.packageName(InternalPackage), "NewContainer").Op("()"),
-				}),
+				jen.Id("c").Op(":=").Op("&").Id("Container").Values(values),
 				jen.Line(),
 			},
 			argumentSetters,
@@ -129,6 +212,22 @@ func (g *PublicContainerGenerator) generateConstructor(arguments []jen.Code, arg
 }
 
 func (g *PublicContainerGenerator) generateGetter(service *ServiceDefinition, container *ContainerDefinition) *jen.Statement {
+	getter := jen.Id("c").Dot("c").Do(g.containerPath(container)).Dot(service.Title()).Call(jen.Id("ctx"))
+	body := []jen.Code{
+		jen.Id("s").Op("=").Add(getter),
+		jen.Id("err").Op("=").Add(g.initError()),
+	}
+	if g.params.ErrorHandling.Explicit {
+		body = []jen.Code{jen.List(jen.Id("s"), jen.Err()).Op("=").Add(getter)}
+	}
+	body = append(body,
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Id("s"), g.params.wrapServiceError("get", service.PublicTitle(), jen.Err())),
+		),
+		jen.Line(),
+		jen.Return(jen.Id("s"), jen.Nil()),
+	)
+
 	return jen.Func().
 		Params(
 			jen.Id("c").Op("*").Id("Container"),
@@ -141,27 +240,38 @@ func (g *PublicContainerGenerator) generateGetter(service *ServiceDefinition, co
 			jen.Id("s").Do(g.container.Type(service.Type)),
 			jen.Err().Error(),
 		).
-		Block(
-			jen.Id("c").Dot("mu").Dot("Lock").Call(),
-			jen.Defer().Id("c").Dot("mu").Dot("Unlock").Call(),
-			jen.Line(),
-			jen.Defer().Func().Call().Block(
-				jen.If(
-					jen.Id("recovered").Op(":=").Recover(),
-					jen.Id("recovered").Op("!=").Nil(),
-				).Block(
-					jen.Err().Op("=").Id("newRecoveredError").Call(
-						jen.Id("recovered"),
-						jen.Id("c").Dot("c").Dot("Error").Call(),
-					),
-				),
-			).Call(),
-			jen.Line(),
-			jen.Id("s").Op("=").Id("c").Dot("c").Do(g.containerPath(container)).Dot(service.Title()).Call(jen.Id("ctx")),
-			jen.Id("err").Op("=").Id("c").Dot("c").Dot("Error").Call(),
-			jen.Line(),
-			jen.Return(jen.Id("s"), jen.Err()),
+		Block(g.guarded(body)...)
+}
+
+// generateNamedGetter generates resolving of services by name, see "named" option of service definitions.
+func (g *PublicContainerGenerator) generateNamedGetter() *jen.Statement {
+	getter := jen.Id("c").Dot("c").Dot("Get").Call(jen.Id("ctx"), jen.Id("name"))
+	body := []jen.Code{jen.List(jen.Id("s"), jen.Err()).Op("=").Add(getter)}
+	if !g.params.ErrorHandling.Explicit {
+		body = append(body,
+			jen.If(jen.Err().Op("==").Nil()).Block(
+				jen.Err().Op("=").Add(g.initError()),
+			),
 		)
+	}
+	body = append(body,
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Id("s"), g.params.wrapNamedServiceError("get", jen.Id("name"), jen.Err())),
+		),
+		jen.Line(),
+		jen.Return(jen.Id("s"), jen.Nil()),
+	)
+
+	return jen.Comment("Get returns the service by the name qualified by the name of attached container,").
+		Line().
+		Comment("for example \"Repositories.EntityRepository\". Only services with the \"named\" option are available.").
+		Line().
+		Func().
+		Params(jen.Id("c").Op("*").Id("Container")).
+		Id("Get").
+		Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("name").String()).
+		Params(jen.Id("s").Any(), jen.Err().Error()).
+		Block(g.guarded(body)...)
 }
 
 func (g *PublicContainerGenerator) generateSetter(service *ServiceDefinition, container *ContainerDefinition) *jen.Statement {
@@ -184,6 +294,90 @@ func (g *PublicContainerGenerator) generateSetter(service *ServiceDefinition, co
 		)
 }
 
+func (g *PublicContainerGenerator) generateHooksSetter() *jen.Statement {
+	return jen.Comment("SetHooks sets hooks observing construction of services, for example to log or trace factories.").
+		Line().
+		Func().
+		Id("SetHooks").
+		Params(jen.Id("hooks").Id("Hooks")).
+		Params(jen.Id("Injector")).
+		Block(
+			jen.Return(
+				jen.Func().Params(jen.Id("c").Op("*").Id("Container")).Params(jen.Error()).Block(
+					jen.Id("c").Dot("c").Dot("SetHooks").Call(jen.Id("hooks")),
+					jen.Line(),
+					jen.Return(jen.Nil()),
+				),
+			),
+		)
+}
+
+func (g *PublicContainerGenerator) generateDescribe() []jen.Code {
+	return []jen.Code{
+		jen.Line(),
+		jen.Line(),
+		jen.Comment("Describe returns all services of the container with their runtime state:"),
+		jen.Line(),
+		jen.Comment("initialization status, the last error, construction time and order of initialization."),
+		jen.Line(),
+		jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
+			Id("Describe").Params().Index().Id("ServiceInfo").
+			Block(
+				g.lock(),
+				jen.Return(jen.Id("c").Dot("c").Dot("Describe").Call()),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Comment("DescribeHandler returns the HTTP handler rendering the description of services as JSON,"),
+		jen.Line(),
+		jen.Comment("it can be used for debug endpoints."),
+		jen.Line(),
+		jen.Func().Params(jen.Id("c").Op("*").Id("Container")).
+			Id("DescribeHandler").Params().Qual("net/http", "Handler").
+			Block(
+				jen.Return(jen.Qual("net/http", "HandlerFunc").Call(
+					jen.Func().Params(
+						jen.Id("w").Qual("net/http", "ResponseWriter"),
+						jen.Id("r").Op("*").Qual("net/http", "Request"),
+					).Block(
+						jen.List(jen.Id("data"), jen.Err()).Op(":=").Qual("encoding/json", "Marshal").Call(
+							jen.Id("c").Dot("Describe").Call(),
+						),
+						jen.If(jen.Err().Op("!=").Nil()).Block(
+							jen.Qual("net/http", "Error").Call(
+								jen.Id("w"),
+								jen.Err().Dot("Error").Call(),
+								jen.Qual("net/http", "StatusInternalServerError"),
+							),
+							jen.Return(),
+						),
+						jen.Line(),
+						jen.Id("w").Dot("Header").Call().Dot("Set").Call(jen.Lit("Content-Type"), jen.Lit("application/json")),
+						jen.List(jen.Id("_"), jen.Id("_")).Op("=").Id("w").Dot("Write").Call(jen.Id("data")),
+					),
+				)),
+			),
+	}
+}
+
+func (g *PublicContainerGenerator) generateOverride() *jen.Statement {
+	return jen.Comment("Override replaces the service by the name with the fake. It is used by the generated ditest package,").
+		Line().
+		Comment("use typed overrides of the ditest package instead.").
+		Line().
+		Func().
+		Id("Override").
+		Params(jen.Id("name").String(), jen.Id("service").Any()).
+		Params(jen.Id("Injector")).
+		Block(
+			jen.Return(
+				jen.Func().Params(jen.Id("c").Op("*").Id("Container")).Params(jen.Error()).Block(
+					jen.Return(jen.Id("c").Dot("c").Dot("Override").Call(jen.Id("name"), jen.Id("service"))),
+				),
+			),
+		)
+}
+
 func (g *PublicContainerGenerator) generateConstructorArgument(service *ServiceDefinition) *jen.Statement {
 	return jen.Id(strcase.ToLowerCamel(service.Name)).
 		Do(g.container.Type(service.Type))
@@ -201,15 +395,135 @@ func (g *PublicContainerGenerator) generateConstructorSetter(service *ServiceDef
 func (g *PublicContainerGenerator) generateCloser() *jen.Statement {
 	return jen.Func().
 		Params(jen.Id("c").Op("*").Id("Container")).
-		Id("Close").Params().
+		Id("Close").Params(jen.Id("ctx").Qual("context", "Context")).Error().
 		Block(
-			jen.Id("c").Dot("mu").Dot("Lock").Call(),
-			jen.Defer().Id("c").Dot("mu").Dot("Unlock").Call(),
-			jen.Line(),
-			jen.Id("c").Dot("c").Dot("Close").Call(),
+			g.lock(),
+			jen.Return(jen.Id("c").Dot("c").Dot("Close").Call(jen.Id("ctx"))),
 		)
 }
 
+func (g *PublicContainerGenerator) generateRun() []jen.Code {
+	getter := jen.Id("c").Dot("c").Dot("Runners").Call(jen.Id("ctx"))
+	body := []jen.Code{
+		jen.Id("runners").Op("=").Add(getter),
+		jen.Id("err").Op("=").Add(g.initError()),
+		jen.Line(),
+		jen.Return(jen.Id("runners"), jen.Err()),
+	}
+	if g.params.ErrorHandling.Explicit {
+		body = []jen.Code{jen.Return(getter)}
+	}
+
+	return []jen.Code{
+		jen.Line(),
+		jen.Comment("Run starts all long-running services in order of their initialization and blocks until"),
+		jen.Line(),
+		jen.Comment("the context is cancelled or any of the services fails. Then services are stopped in reverse order."),
+		jen.Line(),
+		jen.Func().
+			Params(jen.Id("c").Op("*").Id("Container")).
+			Id("Run").
+			Params(jen.Id("ctx").Qual("context", "Context")).
+			Error().
+			Block(
+				jen.List(jen.Id("runners"), jen.Err()).Op(":=").Id("c").Dot("runners").Call(jen.Id("ctx")),
+				jen.If(jen.Err().Op("!=").Nil()).Block(
+					jen.Return(jen.Err()),
+				),
+				jen.Line(),
+				jen.Return(jen.Qual(g.params.packageName(InternalPackage), "Run").Call(jen.Id("ctx"), jen.Id("runners"))),
+			),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().
+			Params(jen.Id("c").Op("*").Id("Container")).
+			Id("runners").
+			Params(jen.Id("ctx").Qual("context", "Context")).
+			Params(
+				jen.Id("runners").Index().Qual(g.params.packageName(InternalPackage), "Runner"),
+				jen.Err().Error(),
+			).
+			Block(g.guarded(body)...),
+	}
+}
+
+// lock generates locking of the public container in the global concurrency mode.
+// In the service concurrency mode the internal container is synchronized by itself.
+func (g *PublicContainerGenerator) lock() jen.Code {
+	if g.isConcurrent() {
+		return jen.Null()
+	}
+
+	return jen.Add(
+		jen.Id("c").Dot("mu").Dot("Lock").Call(),
+		jen.Line(),
+		jen.Defer().Id("c").Dot("mu").Dot("Unlock").Call(),
+		jen.Line(),
+	)
+}
+
+// guarded generates the body of the method resolving services: the body is executed under the lock
+// of the container and recovered panics are returned as errors.
+func (g *PublicContainerGenerator) guarded(body []jen.Code) []jen.Code {
+	return slices.Concat(
+		[]jen.Code{
+			g.lock(),
+			g.initErrors(),
+			jen.Defer().Func().Call().Block(
+				jen.If(
+					jen.Id("recovered").Op(":=").Recover(),
+					jen.Id("recovered").Op("!=").Nil(),
+				).Block(
+					jen.Err().Op("=").Id("newRecoveredError").Call(
+						jen.Id("recovered"),
+						g.initError(),
+					),
+				),
+			).Call(),
+			jen.Line(),
+		},
+		body,
+	)
+}
+
+// initErrors generates the collector of initialization errors for the isolate error policy.
+// Only errors of the requested services and their dependencies are returned by the call.
+func (g *PublicContainerGenerator) initErrors() jen.Code {
+	if g.params.ErrorHandling.Policy != IsolateErrorPolicy {
+		return jen.Null()
+	}
+
+	return jen.List(jen.Id("ctx"), jen.Id("errs")).Op(":=").
+		Qual(g.params.packageName(InternalPackage), "WithInitErrors").Call(jen.Id("ctx"))
+}
+
+func (g *PublicContainerGenerator) initError() *jen.Statement {
+	if g.params.ErrorHandling.Explicit {
+		return jen.Err()
+	}
+	if g.params.ErrorHandling.Policy == IsolateErrorPolicy {
+		return jen.Id("errs").Dot("Err").Call()
+	}
+
+	return jen.Id("c").Dot("c").Dot("Error").Call()
+}
+
+func (g *PublicContainerGenerator) isConcurrent() bool {
+	return g.params.Concurrency == ServiceConcurrency
+}
+
+func (g *PublicContainerGenerator) hasNamedServices() bool {
+	return g.container.HasService(func(service *ServiceDefinition) bool {
+		return service.IsNamed
+	})
+}
+
+func (g *PublicContainerGenerator) hasRunners() bool {
+	return g.container.HasService(func(service *ServiceDefinition) bool {
+		return service.IsRunnable()
+	})
+}
+
 func (g *PublicContainerGenerator) containerPath(container *ContainerDefinition) func(*jen.Statement) {
 	return func(statement *jen.Statement) {
 		if container == nil {
@@ -234,10 +548,7 @@ func (g *PublicContainerGenerator) generateErrorHandler() []jen.Code {
 			).
 			Error().
 			Block(
-				jen.Id("r").Op(":=").Qual(g.params.ErrorHandling.Wrap.Package, g.params.ErrorHandling.Wrap.Function).Call(
-					jen.Lit("panic: %v"),
-					jen.Id("recovered"),
-				),
+				jen.Id("r").Op(":=").Add(g.params.newError("panic: %v", jen.Id("recovered"))),
 				jen.If(jen.Err().Op("!=").Nil()).Block(
 					jen.Return(
 						g.params.joinErrors(
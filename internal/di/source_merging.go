@@ -0,0 +1,155 @@
+package di
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+
+	"github.com/muonsoft/errors"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// mergeDeclarations appends declarations of the generated file to the source of the same package.
+// Imports of the generated file are merged with imports of the source: the alias of the source is used
+// for the already imported package, a new import gets a unique alias if its name is already taken
+// by another import or by a declaration of the source.
+func mergeDeclarations(source, generated []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", source, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Errorf("%w: %w", ErrParsing, err)
+	}
+	generatedFile, err := parser.ParseFile(fset, "", generated, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Errorf("%w: generated code: %w", ErrParsing, err)
+	}
+
+	names, taken, err := importedNames(file)
+	if err != nil {
+		return nil, err
+	}
+	renames := make(map[string]string)
+	for _, spec := range generatedFile.Imports {
+		imp, err := parseImportDefinition(spec)
+		if err != nil {
+			return nil, err
+		}
+		if name, exists := names[imp.Path]; exists {
+			renames[imp.ID] = name
+			continue
+		}
+
+		name := imp.ID
+		for i := 2; taken[name]; i++ {
+			name = imp.ID + strconv.Itoa(i)
+		}
+		// aliases are kept as is, the name of the package may differ from its path
+		alias := name
+		if spec.Name == nil && name == imp.ID {
+			alias = ""
+		}
+		astutil.AddNamedImport(fset, file, alias, imp.Path)
+		names[imp.Path] = name
+		taken[name] = true
+		renames[imp.ID] = name
+	}
+	renamePackageReferences(generatedFile, renames)
+
+	declarations, err := printDeclarations(generatedFile, fset)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err := format.Node(&buffer, fset, file); err != nil {
+		return nil, errors.Errorf("print source: %w", err)
+	}
+	buffer.WriteString("\n")
+	buffer.Write(declarations)
+
+	merged, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return nil, errors.Errorf("%w: merged source: %w", ErrParsing, err)
+	}
+
+	return merged, nil
+}
+
+// importedNames returns local names of packages imported by the file and names
+// which cannot be used as aliases of new imports.
+func importedNames(file *ast.File) (map[string]string, map[string]bool, error) {
+	names := make(map[string]string, len(file.Imports))
+	taken := make(map[string]bool, len(file.Imports))
+
+	for _, spec := range file.Imports {
+		imp, err := parseImportDefinition(spec)
+		if err != nil {
+			return nil, nil, err
+		}
+		taken[imp.ID] = true
+		if imp.ID != "_" && imp.ID != "." {
+			names[imp.Path] = imp.ID
+		}
+	}
+	for name, object := range file.Scope.Objects {
+		if object.Kind != ast.Bad {
+			taken[name] = true
+		}
+	}
+
+	return names, taken, nil
+}
+
+// renamePackageReferences replaces names of packages in qualified identifiers.
+// Only unresolved identifiers refer to imported packages.
+func renamePackageReferences(file *ast.File, renames map[string]string) {
+	for _, decl := range file.Decls {
+		ast.Inspect(decl, func(node ast.Node) bool {
+			selector, ok := node.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if id, ok := selector.X.(*ast.Ident); ok && id.Obj == nil {
+				if name, exists := renames[id.Name]; exists {
+					id.Name = name
+				}
+			}
+
+			return true
+		})
+	}
+}
+
+// printDeclarations prints the file without the package clause and imports.
+func printDeclarations(file *ast.File, fset *token.FileSet) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := format.Node(&buffer, fset, file); err != nil {
+		return nil, errors.Errorf("print generated code: %w", err)
+	}
+	code := buffer.Bytes()
+
+	// positions are changed by renaming, so the printed code is parsed again
+	printedSet := token.NewFileSet()
+	printed, err := parser.ParseFile(printedSet, "", code, parser.ImportsOnly)
+	if err != nil {
+		return nil, errors.Errorf("%w: generated code: %w", ErrParsing, err)
+	}
+	end := printed.Name.End()
+	if len(printed.Decls) > 0 {
+		end = printed.Decls[len(printed.Decls)-1].End()
+	}
+
+	return code[printedSet.Position(end).Offset:], nil
+}
+
+func parsePackageName(source []byte) (string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", source, parser.PackageClauseOnly)
+	if err != nil {
+		return "", errors.Errorf("%w: %w", ErrParsing, err)
+	}
+
+	return file.Name.Name, nil
+}
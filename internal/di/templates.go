@@ -12,7 +12,7 @@ const headingTemplate = `// Code generated by DIGEN; DO NOT EDIT.
 // See docs at https://github.com/strider2038/digen
 `
 
-const definitionsContainerFileSkeleton = `package definitions
+const definitionsContainerFileSkeleton = `package %s
 
 // Container is a root dependency injection container. It is required to describe
 // your services.
@@ -31,7 +31,7 @@ type Container struct {
 // }
 `
 
-const bitsetSkeleton = `package internal
+const bitsetSkeleton = `package %s
 
 type bitset []uint64
 
@@ -57,3 +57,27 @@ func (b *bitset) split(n int) (int, int) {
 	return n >> 6, n & 0x3F
 }
 `
+
+const syncBitsetSkeleton = `package %s
+
+import "sync/atomic"
+
+// syncBitset is a fixed size bitset safe for concurrent use.
+type syncBitset []atomic.Uint64
+
+func (b syncBitset) Set(n int) {
+	i, j := n>>6, n&0x3F
+	for {
+		old := b[i].Load()
+		if b[i].CompareAndSwap(old, old|(1<<j)) {
+			return
+		}
+	}
+}
+
+func (b syncBitset) IsSet(n int) bool {
+	i, j := n>>6, n&0x3F
+
+	return b[i].Load()&(1<<j) != 0
+}
+`
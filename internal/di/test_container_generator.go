@@ -0,0 +1,179 @@
+package di
+
+import (
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/iancoleman/strcase"
+)
+
+// TestContainerGenerator generates ditest package with the container for tests.
+// Any service except required ones can be overridden by a fake.
+type TestContainerGenerator struct {
+	fileLocator FileLocator
+	container   *RootContainerDefinition
+	params      GenerationParameters
+}
+
+func NewTestContainerGenerator(
+	fileLocator FileLocator,
+	container *RootContainerDefinition,
+	params GenerationParameters,
+) *TestContainerGenerator {
+	return &TestContainerGenerator{
+		fileLocator: fileLocator,
+		container:   container,
+		params:      params,
+	}
+}
+
+func (g *TestContainerGenerator) Generate() (*File, error) {
+	file := NewFileBuilder(g.fileLocator.GetPackageFilePath(TestingPackage, "container.go"), g.params.Layout.Testing.Name)
+	file.AddHeading(g.params)
+	file.AddImportAliases(g.container.Imports)
+
+	required := make([]*ServiceDefinition, 0)
+	overrides := make([]jen.Code, 0, g.container.ServicesCount())
+	add := func(service *ServiceDefinition) {
+		if service.IsRequired {
+			required = append(required, service)
+		} else {
+			overrides = append(overrides, jen.Line(), jen.Line(), g.generateOverride(service))
+		}
+	}
+	for _, service := range g.container.Services {
+		add(service)
+	}
+	for _, container := range g.container.Containers {
+		for _, service := range container.Services {
+			add(service)
+		}
+	}
+
+	file.Add(
+		jen.Comment("Override replaces the service of the test container by the fake, see Override functions."),
+		jen.Line(),
+		jen.Comment("Any injector of the container can be used as well."),
+		jen.Line(),
+		jen.Type().Id("Override").Op("=").Qual(g.params.RootPackage, "Injector"),
+		jen.Line(),
+		jen.Line(),
+		g.generateTestContainer(required),
+		jen.Line(),
+		jen.Line(),
+		g.generateConstructor(required),
+		jen.Line(),
+		jen.Line(),
+		g.generateClone(required),
+	)
+	file.Add(overrides...)
+
+	return file.GetFile()
+}
+
+func (g *TestContainerGenerator) generateTestContainer(required []*ServiceDefinition) *jen.Statement {
+	fields := make([]jen.Code, 0, len(required)+4)
+	fields = append(fields, jen.Op("*").Qual(g.params.RootPackage, "Container"), jen.Line())
+	if g.params.Factories.Struct != "" {
+		fields = append(fields, jen.Id("factories").Op("*").Qual(g.params.RootPackage, g.params.Factories.Struct))
+	}
+	for _, service := range required {
+		fields = append(fields, jen.Id(strcase.ToLowerCamel(service.Name)).Do(g.container.Type(service.Type)))
+	}
+	fields = append(fields, jen.Id("overrides").Index().Id("Override"))
+
+	return jen.Comment("TestContainer is the container for tests, it is closed by the cleanup of the test.").
+		Line().
+		Type().Id("TestContainer").Struct(fields...)
+}
+
+func (g *TestContainerGenerator) generateConstructor(required []*ServiceDefinition) *jen.Statement {
+	arguments := make([]jen.Code, 0, len(required)+2)
+	arguments = append(arguments, jen.Id("tb").Qual("testing", "TB"))
+	constructorArguments := make([]jen.Code, 0, len(required)+2)
+	values := jen.Dict{
+		jen.Id("Container"): jen.Id("c"),
+		jen.Id("overrides"): jen.Id("overrides"),
+	}
+	if g.params.Factories.Struct != "" {
+		arguments = append(arguments, jen.Id("factories").Op("*").Qual(g.params.RootPackage, g.params.Factories.Struct))
+		constructorArguments = append(constructorArguments, jen.Id("factories"))
+		values[jen.Id("factories")] = jen.Id("factories")
+	}
+	for _, service := range required {
+		name := strcase.ToLowerCamel(service.Name)
+		arguments = append(arguments, jen.Id(name).Do(g.container.Type(service.Type)))
+		constructorArguments = append(constructorArguments, jen.Id(name))
+		values[jen.Id(name)] = jen.Id(name)
+	}
+	arguments = append(arguments, jen.Id("overrides").Op("...").Id("Override"))
+	constructorArguments = append(constructorArguments, jen.Id("overrides").Op("..."))
+
+	return jen.Comment("NewTestContainer creates the container for the test with overridden services.").
+		Line().
+		Comment("The container is closed when the test and all its subtests complete.").
+		Line().
+		Func().Id("NewTestContainer").
+		Params(arguments...).
+		Op("*").Id("TestContainer").
+		Block(
+			jen.Id("tb").Dot("Helper").Call(),
+			jen.Line(),
+			jen.List(jen.Id("c"), jen.Err()).Op(":=").
+				Qual(g.params.RootPackage, "NewContainer").Call(constructorArguments...),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Id("tb").Dot("Fatalf").Call(jen.Lit("create test container: %v"), jen.Err()),
+			),
+			jen.Id("tb").Dot("Cleanup").Call(jen.Func().Params().Block(
+				jen.If(
+					jen.Err().Op(":=").Id("c").Dot("Close").Call(jen.Qual("context", "Background").Call()),
+					jen.Err().Op("!=").Nil(),
+				).Block(
+					jen.Id("tb").Dot("Errorf").Call(jen.Lit("close test container: %v"), jen.Err()),
+				),
+			)),
+			jen.Line(),
+			jen.Return(jen.Op("&").Id("TestContainer").Values(values)),
+		)
+}
+
+func (g *TestContainerGenerator) generateClone(required []*ServiceDefinition) *jen.Statement {
+	arguments := make([]jen.Code, 0, len(required)+2)
+	arguments = append(arguments, jen.Id("tb"))
+	if g.params.Factories.Struct != "" {
+		arguments = append(arguments, jen.Id("c").Dot("factories"))
+	}
+	for _, service := range required {
+		arguments = append(arguments, jen.Id("c").Dot(strcase.ToLowerCamel(service.Name)))
+	}
+	arguments = append(arguments,
+		jen.Qual("slices", "Concat").Call(jen.Id("c").Dot("overrides"), jen.Id("overrides")).Op("..."),
+	)
+
+	return jen.Comment("Clone creates a new container with the same required services and overrides, for example").
+		Line().
+		Comment("for parallel subtests. Services are not shared with the original container.").
+		Line().
+		Func().Params(jen.Id("c").Op("*").Id("TestContainer")).
+		Id("Clone").
+		Params(jen.Id("tb").Qual("testing", "TB"), jen.Id("overrides").Op("...").Id("Override")).
+		Op("*").Id("TestContainer").
+		Block(
+			jen.Id("tb").Dot("Helper").Call(),
+			jen.Line(),
+			jen.Return(jen.Id("NewTestContainer").Call(arguments...)),
+		)
+}
+
+func (g *TestContainerGenerator) generateOverride(service *ServiceDefinition) *jen.Statement {
+	name := "Override" + strings.Title(service.Prefix) + service.Title()
+
+	return jen.Commentf("%s replaces %s service by the fake.", name, service.FullName()).
+		Line().
+		Func().Id(name).
+		Params(jen.Id("s").Do(g.container.Type(service.Type))).
+		Id("Override").
+		Block(
+			jen.Return(jen.Qual(g.params.RootPackage, "Override").Call(jen.Lit(service.FullName()), jen.Id("s"))),
+		)
+}
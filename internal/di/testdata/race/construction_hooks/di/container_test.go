@@ -0,0 +1,100 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"example.com/test/di"
+	"example.com/test/domain"
+)
+
+type depthKey struct{}
+
+type recordingHooks struct {
+	mu     sync.Mutex
+	events []string
+	errs   map[string]error
+}
+
+func (h *recordingHooks) OnBuildStart(ctx context.Context, id string) context.Context {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, "start "+id)
+	depth, _ := ctx.Value(depthKey{}).(int)
+
+	return context.WithValue(ctx, depthKey{}, depth+1)
+}
+
+func (h *recordingHooks) OnBuildDone(ctx context.Context, id string, duration time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if depth, _ := ctx.Value(depthKey{}).(int); depth == 0 {
+		panic("context of OnBuildStart is not passed to OnBuildDone")
+	}
+	if duration < 0 {
+		panic("negative duration")
+	}
+	h.events = append(h.events, "done "+id)
+	if h.errs == nil {
+		h.errs = map[string]error{}
+	}
+	h.errs[id] = err
+}
+
+func TestContainer_Hooks(t *testing.T) {
+	hooks := &recordingHooks{}
+	c, err := di.NewContainer(&domain.Config{}, di.SetHooks(hooks))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Handler(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	want := []string{
+		"start Handler",
+		"start Repositories.EntityRepository",
+		"start Connection",
+		"done Connection",
+		"done Repositories.EntityRepository",
+		"done Handler",
+	}
+	if !slices.Equal(hooks.events, want) {
+		t.Fatalf("want events %v, got %v", want, hooks.events)
+	}
+}
+
+func TestContainer_HooksWithError(t *testing.T) {
+	config := &domain.Config{}
+	config.Unavailable.Store(true)
+	hooks := &recordingHooks{}
+	c, err := di.NewContainer(config, di.SetHooks(hooks))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Handler(context.Background())
+
+	if !errors.Is(err, domain.ErrConnectionRefused) {
+		t.Fatalf("want connection error, got %v", err)
+	}
+	if !errors.Is(hooks.errs["Connection"], domain.ErrConnectionRefused) {
+		t.Fatalf("want connection error in hooks, got %v", hooks.errs["Connection"])
+	}
+	if hooks.errs["Handler"] != nil {
+		t.Fatalf("want no error of Handler factory in hooks, got %v", hooks.errs["Handler"])
+	}
+}
@@ -0,0 +1,24 @@
+package factories
+
+import (
+	"context"
+
+	"example.com/test/di/lookup"
+	"example.com/test/domain"
+)
+
+func CreateConnection(ctx context.Context, c lookup.Container) (*domain.Connection, error) {
+	if c.Config(ctx).Unavailable.Load() {
+		return nil, domain.ErrConnectionRefused
+	}
+
+	return &domain.Connection{}, nil
+}
+
+func CreateHandler(ctx context.Context, c lookup.Container) (*domain.Handler, error) {
+	return &domain.Handler{Repository: c.Repositories().EntityRepository(ctx)}, nil
+}
+
+func CreateClock(ctx context.Context, c lookup.Container) (*domain.Clock, error) {
+	return &domain.Clock{}, nil
+}
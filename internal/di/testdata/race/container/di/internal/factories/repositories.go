@@ -0,0 +1,20 @@
+package factories
+
+import (
+	"context"
+
+	"example.com/test/di/lookup"
+	"example.com/test/domain"
+)
+
+type entityRepository struct {
+	connection *domain.Connection
+}
+
+func (r *entityRepository) Connection() *domain.Connection {
+	return r.connection
+}
+
+func CreateRepositoriesEntityRepository(ctx context.Context, c lookup.Container) (domain.EntityRepository, error) {
+	return &entityRepository{connection: c.Connection(ctx)}, nil
+}
@@ -0,0 +1,83 @@
+package di_test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"example.com/test/di"
+)
+
+func TestContainer_EnvParameters(t *testing.T) {
+	t.Setenv("DIGEN_TEST_BACKEND", "http://backend:8080")
+	t.Setenv("DIGEN_TEST_HOSTS", "example.com, example.org")
+	c, err := di.NewContainer(di.SetDebug(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Server(ctx); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	server, err := c.Server(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.Port != 3000 {
+		t.Errorf("want default port, got %d", server.Port)
+	}
+	if server.Backend.Host != "backend:8080" {
+		t.Errorf("unexpected backend %v", server.Backend)
+	}
+	if server.Timeout != 5*time.Second {
+		t.Errorf("want default timeout, got %s", server.Timeout)
+	}
+	if !reflect.DeepEqual(server.Hosts, []string{"example.com", "example.org"}) {
+		t.Errorf("unexpected hosts %v", server.Hosts)
+	}
+	if !server.Debug {
+		t.Error("setter is not applied to env service")
+	}
+}
+
+func TestContainer_InvalidEnvParameter(t *testing.T) {
+	t.Setenv("DIGEN_TEST_BACKEND", "http://backend:8080")
+	t.Setenv("DIGEN_TEST_PORT", "http")
+	c, err := di.NewContainer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Server(context.Background())
+
+	want := `get Server: create Server -> Params.Port: invalid DIGEN_TEST_PORT: strconv.ParseInt: parsing "http": invalid syntax`
+	if err == nil || err.Error() != want {
+		t.Fatalf("want error %q, got %v", want, err)
+	}
+}
+
+func TestContainer_MissingEnvParameter(t *testing.T) {
+	c, err := di.NewContainer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Server(context.Background())
+
+	want := "get Server: create Server -> Params.Backend: environment variable DIGEN_TEST_BACKEND is not set"
+	if err == nil || err.Error() != want {
+		t.Fatalf("want error %q, got %v", want, err)
+	}
+}
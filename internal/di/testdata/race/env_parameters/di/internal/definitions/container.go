@@ -0,0 +1,22 @@
+package definitions
+
+import (
+	"net/url"
+	"time"
+
+	"example.com/test/domain"
+)
+
+type Container struct {
+	Server *domain.Server `di:"public"`
+
+	Params ParamsContainer
+}
+
+type ParamsContainer struct {
+	Port    int           `di:"env=DIGEN_TEST_PORT,default=3000,public"`
+	Backend *url.URL      `di:"env=DIGEN_TEST_BACKEND"`
+	Timeout time.Duration `di:"env=DIGEN_TEST_TIMEOUT,default=5s"`
+	Hosts   []string      `di:"env=DIGEN_TEST_HOSTS,default=localhost;127.0.0.1"`
+	Debug   bool          `di:"env=DIGEN_TEST_DEBUG,default=false,set"`
+}
@@ -0,0 +1,18 @@
+package factories
+
+import (
+	"context"
+
+	"example.com/test/di/lookup"
+	"example.com/test/domain"
+)
+
+func CreateServer(ctx context.Context, c lookup.Container) (*domain.Server, error) {
+	return &domain.Server{
+		Port:    c.Params().Port(ctx),
+		Backend: c.Params().Backend(ctx),
+		Timeout: c.Params().Timeout(ctx),
+		Hosts:   c.Params().Hosts(ctx),
+		Debug:   c.Params().Debug(ctx),
+	}, nil
+}
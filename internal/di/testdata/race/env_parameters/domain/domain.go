@@ -0,0 +1,14 @@
+package domain
+
+import (
+	"net/url"
+	"time"
+)
+
+type Server struct {
+	Port    int
+	Backend *url.URL
+	Timeout time.Duration
+	Hosts   []string
+	Debug   bool
+}
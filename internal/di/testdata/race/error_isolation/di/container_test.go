@@ -0,0 +1,97 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"example.com/test/di"
+	"example.com/test/di/internal/factories"
+	"example.com/test/domain"
+)
+
+func TestContainer_ErrorIsolation(t *testing.T) {
+	config := &domain.Config{}
+	config.Unavailable.Store(true)
+	c, err := di.NewContainer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	_, err = c.Handler(ctx)
+	if !errors.Is(err, domain.ErrConnectionRefused) {
+		t.Fatalf("want connection error, got %v", err)
+	}
+	want := "get Handler: create Handler -> Repositories.EntityRepository -> Connection: connection refused"
+	if err.Error() != want {
+		t.Fatalf("want error %q, got %q", want, err)
+	}
+	var serviceErr *di.ServiceError
+	if !errors.As(err, &serviceErr) {
+		t.Fatalf("want service error, got %T", err)
+	}
+	if serviceErr.ID != "Connection" || len(serviceErr.Path) != 3 {
+		t.Fatalf("unexpected service error: %s %v", serviceErr.ID, serviceErr.Path)
+	}
+
+	clock, err := c.Clock(ctx)
+	if err != nil {
+		t.Fatalf("unrelated service is failed: %v", err)
+	}
+	if clock == nil {
+		t.Fatal("unrelated service is not initialized")
+	}
+
+	// the last error is returned during the retry backoff
+	_, err = c.Handler(ctx)
+	if !errors.Is(err, domain.ErrConnectionRefused) {
+		t.Fatalf("want connection error, got %v", err)
+	}
+	if attempts := factories.ConnectionAttempts.Load(); attempts != 1 {
+		t.Fatalf("connection created %d times during backoff", attempts)
+	}
+
+	config.Unavailable.Store(false)
+	time.Sleep(60 * time.Millisecond)
+
+	handler, err := c.Handler(ctx)
+	if err != nil {
+		t.Fatalf("service is not retried: %v", err)
+	}
+	if handler.Repository.Connection() == nil {
+		t.Fatal("dependency is not initialized")
+	}
+	if attempts := factories.ConnectionAttempts.Load(); attempts != 2 {
+		t.Fatalf("connection created %d times", attempts)
+	}
+}
+
+func TestContainer_ConcurrentFailures(t *testing.T) {
+	config := &domain.Config{}
+	config.Unavailable.Store(true)
+	c, err := di.NewContainer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Handler(context.Background()); !errors.Is(err, domain.ErrConnectionRefused) {
+				t.Errorf("want connection error, got %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := c.Clock(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
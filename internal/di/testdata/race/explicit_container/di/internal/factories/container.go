@@ -0,0 +1,37 @@
+package factories
+
+import (
+	"context"
+	"sync/atomic"
+
+	"example.com/test/di/lookup"
+	"example.com/test/domain"
+)
+
+var ConnectionAttempts atomic.Int32
+
+func CreateConnection(ctx context.Context, c lookup.Container) (*domain.Connection, error) {
+	ConnectionAttempts.Add(1)
+	config, err := c.Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if config.Unavailable.Load() {
+		return nil, domain.ErrConnectionRefused
+	}
+
+	return &domain.Connection{}, nil
+}
+
+func CreateHandler(ctx context.Context, c lookup.Container) (*domain.Handler, error) {
+	repository, err := c.Repositories().EntityRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Handler{Repository: repository}, nil
+}
+
+func CreateClock(ctx context.Context, c lookup.Container) (*domain.Clock, error) {
+	return &domain.Clock{}, nil
+}
@@ -0,0 +1,25 @@
+package factories
+
+import (
+	"context"
+
+	"example.com/test/di/lookup"
+	"example.com/test/domain"
+)
+
+type entityRepository struct {
+	connection *domain.Connection
+}
+
+func (r *entityRepository) Connection() *domain.Connection {
+	return r.connection
+}
+
+func CreateRepositoriesEntityRepository(ctx context.Context, c lookup.Container) (domain.EntityRepository, error) {
+	connection, err := c.Connection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entityRepository{connection: connection}, nil
+}
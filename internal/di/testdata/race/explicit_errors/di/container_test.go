@@ -0,0 +1,56 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"example.com/test/di"
+	"example.com/test/di/internal/factories"
+	"example.com/test/domain"
+)
+
+func TestContainer_ExplicitErrors(t *testing.T) {
+	config := &domain.Config{}
+	config.Unavailable.Store(true)
+	c, err := di.NewContainer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	handler, err := c.Handler(ctx)
+	if !errors.Is(err, domain.ErrConnectionRefused) {
+		t.Fatalf("want connection error, got %v", err)
+	}
+	want := "get Handler: create Handler -> Repositories.EntityRepository -> Connection: connection refused"
+	if err.Error() != want {
+		t.Fatalf("want error %q, got %q", want, err)
+	}
+	var serviceErr *di.ServiceError
+	if !errors.As(err, &serviceErr) {
+		t.Fatalf("want service error, got %T", err)
+	}
+	if serviceErr.ID != "Connection" || len(serviceErr.Path) != 3 {
+		t.Fatalf("unexpected service error: %s %v", serviceErr.ID, serviceErr.Path)
+	}
+	if handler != nil {
+		t.Fatal("handler is created with failed dependency")
+	}
+
+	if _, err := c.Clock(ctx); err != nil {
+		t.Fatalf("unrelated service is failed: %v", err)
+	}
+
+	config.Unavailable.Store(false)
+	handler, err = c.Handler(ctx)
+	if err != nil {
+		t.Fatalf("service is not retried: %v", err)
+	}
+	if handler.Repository.Connection() == nil {
+		t.Fatal("dependency is not initialized")
+	}
+	if attempts := factories.ConnectionAttempts.Load(); attempts != 2 {
+		t.Fatalf("connection created %d times", attempts)
+	}
+}
@@ -0,0 +1,54 @@
+package di_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"example.com/test/di"
+	"example.com/test/di/ditest"
+	"example.com/test/domain"
+)
+
+func TestNewContainer_FactoriesStruct(t *testing.T) {
+	pool := &domain.Pool{}
+	c, err := di.NewContainer(&di.Factories{Pool: pool})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler, err := c.Handler(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if handler.Connection.Pool != pool || handler.Repository.Connection() != handler.Connection {
+				t.Error("factories state is not shared")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := pool.Acquired.Load(); got != 1 {
+		t.Fatalf("want connection created once, got %d", got)
+	}
+}
+
+func TestNewTestContainer_FactoriesStruct(t *testing.T) {
+	pool := &domain.Pool{}
+	prepared := ditest.NewTestContainer(t, &di.Factories{Pool: pool})
+	c := prepared.Clone(t)
+
+	handler, err := c.Handler(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handler.Connection.Pool != pool {
+		t.Fatal("factories are not cloned")
+	}
+}
@@ -0,0 +1,16 @@
+package definitions
+
+import (
+	"example.com/test/domain"
+)
+
+type Container struct {
+	Connection *domain.Connection
+	Handler    *domain.Handler `di:"public"`
+
+	Repositories RepositoryContainer
+}
+
+type RepositoryContainer struct {
+	EntityRepository domain.EntityRepository
+}
@@ -0,0 +1,22 @@
+package factories
+
+import (
+	"context"
+
+	"example.com/test/di/lookup"
+	"example.com/test/domain"
+)
+
+type Factories struct {
+	Pool *domain.Pool
+}
+
+func (f *Factories) CreateConnection(ctx context.Context, c lookup.Container) (*domain.Connection, error) {
+	f.Pool.Acquired.Add(1)
+
+	return &domain.Connection{Pool: f.Pool}, nil
+}
+
+func (f Factories) CreateHandler(connection *domain.Connection, repositoriesEntityRepository domain.EntityRepository) *domain.Handler {
+	return &domain.Handler{Connection: connection, Repository: repositoriesEntityRepository}
+}
@@ -0,0 +1,17 @@
+package factories
+
+import (
+	"example.com/test/domain"
+)
+
+type entityRepository struct {
+	connection *domain.Connection
+}
+
+func (r *entityRepository) Connection() *domain.Connection {
+	return r.connection
+}
+
+func (f *Factories) CreateRepositoriesEntityRepository(connection *domain.Connection) domain.EntityRepository {
+	return &entityRepository{connection: connection}
+}
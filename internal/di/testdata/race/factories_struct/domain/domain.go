@@ -0,0 +1,21 @@
+package domain
+
+import "sync/atomic"
+
+// Pool is the state shared by factories.
+type Pool struct {
+	Acquired atomic.Int32
+}
+
+type Connection struct {
+	Pool *Pool
+}
+
+type EntityRepository interface {
+	Connection() *Connection
+}
+
+type Handler struct {
+	Connection *Connection
+	Repository EntityRepository
+}
@@ -0,0 +1,45 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"example.com/test/di"
+	"example.com/test/domain"
+)
+
+func TestContainer_Handler(t *testing.T) {
+	c, err := di.NewContainer(&domain.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler, err := c.Handler(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handler.Repository.Connection() == nil {
+		t.Fatal("connection is not passed")
+	}
+	if handler.Version != "v1" {
+		t.Fatalf("want version v1, got %q", handler.Version)
+	}
+	if _, err := c.Clock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContainer_Handler_DependencyError(t *testing.T) {
+	config := &domain.Config{}
+	config.Unavailable.Store(true)
+	c, err := di.NewContainer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Handler(context.Background())
+	if !errors.Is(err, domain.ErrConnectionRefused) {
+		t.Fatalf("want connection error, got %v", err)
+	}
+}
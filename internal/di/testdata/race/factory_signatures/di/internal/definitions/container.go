@@ -0,0 +1,19 @@
+package definitions
+
+import (
+	"example.com/test/domain"
+)
+
+type Container struct {
+	Config     *domain.Config `di:"required"`
+	Connection *domain.Connection
+	Handler    *domain.Handler `di:"public"`
+	Clock      *domain.Clock   `di:"public"`
+	Version    string
+
+	Repositories RepositoryContainer
+}
+
+type RepositoryContainer struct {
+	EntityRepository domain.EntityRepository
+}
@@ -0,0 +1,28 @@
+package factories
+
+import (
+	"context"
+
+	"example.com/test/di/lookup"
+	"example.com/test/domain"
+)
+
+func NewConnection(config *domain.Config) (*domain.Connection, error) {
+	if config.Unavailable.Load() {
+		return nil, domain.ErrConnectionRefused
+	}
+
+	return &domain.Connection{}, nil
+}
+
+func NewHandler(ctx context.Context, repositoriesEntityRepository domain.EntityRepository, version string) *domain.Handler {
+	return &domain.Handler{Repository: repositoriesEntityRepository, Version: version}
+}
+
+func NewClock(_ lookup.Container) *domain.Clock {
+	return &domain.Clock{}
+}
+
+func NewVersion() string {
+	return "v1"
+}
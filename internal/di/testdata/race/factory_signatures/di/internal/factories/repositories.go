@@ -0,0 +1,19 @@
+package factories
+
+import (
+	"context"
+
+	"example.com/test/domain"
+)
+
+type entityRepository struct {
+	connection *domain.Connection
+}
+
+func (r *entityRepository) Connection() *domain.Connection {
+	return r.connection
+}
+
+func NewRepositoriesEntityRepository(ctx context.Context, connection *domain.Connection) (domain.EntityRepository, error) {
+	return &entityRepository{connection: connection}, nil
+}
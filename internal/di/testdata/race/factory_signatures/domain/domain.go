@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+var ErrConnectionRefused = errors.New("connection refused")
+
+type Config struct {
+	Unavailable atomic.Bool
+}
+
+type Connection struct{}
+
+type EntityRepository interface {
+	Connection() *Connection
+}
+
+type Handler struct {
+	Repository EntityRepository
+	Version    string
+}
+
+type Clock struct{}
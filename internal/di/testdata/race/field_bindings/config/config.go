@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+type Params struct {
+	HTTP     HTTP
+	Database Database
+}
+
+type HTTP struct {
+	Port    int
+	Timeout time.Duration
+}
+
+type Database struct {
+	DSN string
+}
+
+type Server struct {
+	Port    int
+	Timeout time.Duration
+	DSN     string
+}
@@ -0,0 +1,59 @@
+package di_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"example.com/test/config"
+	"example.com/test/di"
+)
+
+func TestContainer_FieldBindings(t *testing.T) {
+	params := &config.Params{
+		HTTP:     config.HTTP{Port: 8080, Timeout: time.Second},
+		Database: config.Database{DSN: "postgres://localhost"},
+	}
+	c, err := di.NewContainer(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Server(ctx); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	server, err := c.Server(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := config.Server{Port: 8080, Timeout: time.Second, DSN: "postgres://localhost"}
+	if *server != want {
+		t.Errorf("want server %+v, got %+v", want, *server)
+	}
+}
+
+func TestContainer_OverriddenFieldBinding(t *testing.T) {
+	c, err := di.NewContainer(&config.Params{}, di.SetDSN("sqlite://memory"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := c.Server(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.DSN != "sqlite://memory" {
+		t.Errorf("setter is not applied to field binding, got %q", server.DSN)
+	}
+}
@@ -0,0 +1,21 @@
+package definitions
+
+import (
+	"time"
+
+	"example.com/test/config"
+)
+
+type Container struct {
+	Config *config.Params `di:"required"`
+	Port   int            `di:"from=Config.HTTP.Port,public"`
+	Server *config.Server `di:"public"`
+
+	Params ParamsContainer
+}
+
+type ParamsContainer struct {
+	Database config.Database `di:"from=Config.Database"`
+	DSN      string          `di:"from=Params.Database.DSN,set"`
+	Timeout  time.Duration   `di:"from=Config.HTTP.Timeout"`
+}
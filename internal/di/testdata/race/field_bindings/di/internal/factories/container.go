@@ -0,0 +1,25 @@
+package factories
+
+import (
+	"context"
+
+	"example.com/test/config"
+	"example.com/test/di/lookup"
+)
+
+func CreateServer(ctx context.Context, c lookup.Container) (*config.Server, error) {
+	port, err := c.Port(ctx)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := c.Params().Timeout(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dsn, err := c.Params().DSN(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config.Server{Port: port, Timeout: timeout, DSN: dsn}, nil
+}
@@ -0,0 +1,45 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"example.com/test/di"
+)
+
+func TestContainer_LiteralValues(t *testing.T) {
+	c, err := di.NewContainer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := c.Client(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.BaseURL.String() != "https://example.com/api" {
+		t.Errorf("unexpected base url %v", client.BaseURL)
+	}
+	if client.Timeout != 90*time.Second {
+		t.Errorf("unexpected timeout %s", client.Timeout)
+	}
+	if client.PageSize != 50 || client.Ratio != 0.25 || client.Agent != "digen" {
+		t.Errorf("unexpected client %+v", client)
+	}
+}
+
+func TestContainer_OverriddenLiteralValue(t *testing.T) {
+	c, err := di.NewContainer(di.SetTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := c.Client(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Timeout != time.Second {
+		t.Errorf("setter is not applied to literal value, got %s", client.Timeout)
+	}
+}
@@ -0,0 +1,22 @@
+package definitions
+
+import (
+	"net/url"
+	"time"
+
+	"example.com/test/domain"
+)
+
+type Container struct {
+	Client *domain.Client `di:"public"`
+
+	Params ParamsContainer
+}
+
+type ParamsContainer struct {
+	BaseURL  *url.URL      `di:"value=https://example.com/api"`
+	Timeout  time.Duration `di:"value=1m30s,set"`
+	PageSize uint16        `di:"value=50,public"`
+	Ratio    float64       `di:"value=0.25"`
+	Agent    string        `di:"value=digen"`
+}
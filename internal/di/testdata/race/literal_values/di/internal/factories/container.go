@@ -0,0 +1,33 @@
+package factories
+
+import (
+	"context"
+
+	"example.com/test/di/lookup"
+	"example.com/test/domain"
+)
+
+func CreateClient(ctx context.Context, c lookup.Container) (*domain.Client, error) {
+	baseURL, err := c.Params().BaseURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := c.Params().Timeout(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pageSize, err := c.Params().PageSize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ratio, err := c.Params().Ratio(ctx)
+	if err != nil {
+		return nil, err
+	}
+	agent, err := c.Params().Agent(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Client{BaseURL: baseURL, Timeout: timeout, PageSize: pageSize, Ratio: ratio, Agent: agent}, nil
+}
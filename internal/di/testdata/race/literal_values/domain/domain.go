@@ -0,0 +1,14 @@
+package domain
+
+import (
+	"net/url"
+	"time"
+)
+
+type Client struct {
+	BaseURL  *url.URL
+	Timeout  time.Duration
+	PageSize uint16
+	Ratio    float64
+	Agent    string
+}
@@ -0,0 +1,20 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"example.com/test/di"
+	"example.com/test/domain"
+)
+
+func TestContainer_Handler(t *testing.T) {
+	c, err := di.NewContainer(&domain.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Handler(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,47 @@
+package factories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"example.com/test/di/internal/factories"
+	"example.com/test/di/lookup/lookuptest"
+	"example.com/test/domain"
+)
+
+func TestCreateRepositoriesEntityRepository(t *testing.T) {
+	connection := &domain.Connection{}
+	c := &lookuptest.Container{ConnectionFunc: lookuptest.Value(connection)}
+
+	repository, err := factories.CreateRepositoriesEntityRepository(context.Background(), c)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repository.Connection() != connection {
+		t.Fatal("unexpected connection")
+	}
+}
+
+func TestCreateHandler(t *testing.T) {
+	c := &lookuptest.Container{}
+	c.RepositoriesContainer.EntityRepositoryFunc = lookuptest.Error[domain.EntityRepository](domain.ErrConnectionRefused)
+
+	_, err := factories.CreateHandler(context.Background(), c)
+
+	if !errors.Is(err, domain.ErrConnectionRefused) {
+		t.Fatalf("want connection error, got %v", err)
+	}
+}
+
+func TestCreateConnection_UnsetService(t *testing.T) {
+	defer func() {
+		want := "lookuptest: service Config is not set"
+		if recovered := recover(); recovered != want {
+			t.Fatalf("want panic %q, got %v", want, recovered)
+		}
+	}()
+
+	factories.CreateConnection(context.Background(), &lookuptest.Container{})
+}
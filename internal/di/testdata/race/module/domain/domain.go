@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+var ErrConnectionRefused = errors.New("connection refused")
+
+type Config struct {
+	Unavailable atomic.Bool
+}
+
+type Connection struct{}
+
+type EntityRepository interface {
+	Connection() *Connection
+}
+
+type Handler struct {
+	Repository EntityRepository
+}
+
+type Clock struct {
+	Closed atomic.Bool
+}
+
+func (c *Clock) Close() {
+	c.Closed.Store(true)
+}
@@ -0,0 +1,71 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"example.com/test/di"
+	"example.com/test/domain"
+)
+
+func TestContainer_Get(t *testing.T) {
+	config := &domain.Config{}
+	config.Unavailable.Store(true)
+	c, err := di.NewContainer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	_, err = c.Get(ctx, "Repositories.EntityRepository")
+	want := "get Repositories.EntityRepository: create Repositories.EntityRepository -> Connection: connection refused"
+	if err == nil || err.Error() != want {
+		t.Fatalf("want error %q, got %v", want, err)
+	}
+
+	config.Unavailable.Store(false)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := c.Get(ctx, "Repositories.EntityRepository")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if _, ok := s.(domain.EntityRepository); !ok {
+				t.Errorf("unexpected service type %T", s)
+			}
+		}()
+	}
+	wg.Wait()
+
+	connection, err := c.Get(ctx, "Connection")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, err := c.Handler(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handler.Repository.Connection() != connection {
+		t.Fatal("named service is not shared with getters")
+	}
+}
+
+func TestContainer_GetPrivateService(t *testing.T) {
+	c, err := di.NewContainer(&domain.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"Handler", "Clock", "Config", "Unknown", "EntityRepository"} {
+		_, err := c.Get(context.Background(), name)
+		if !errors.Is(err, di.ErrServiceNotFound) {
+			t.Errorf("want not found error for %s, got %v", name, err)
+		}
+	}
+}
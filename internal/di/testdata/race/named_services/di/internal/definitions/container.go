@@ -0,0 +1,18 @@
+package definitions
+
+import (
+	"example.com/test/domain"
+)
+
+type Container struct {
+	Config     *domain.Config     `di:"required"`
+	Connection *domain.Connection `di:"named"`
+	Handler    *domain.Handler    `di:"public"`
+	Clock      *domain.Clock      `di:"public"`
+
+	Repositories RepositoryContainer
+}
+
+type RepositoryContainer struct {
+	EntityRepository domain.EntityRepository `di:"named"`
+}
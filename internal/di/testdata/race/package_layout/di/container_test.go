@@ -0,0 +1,48 @@
+package container_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	container "example.com/test/di"
+	"example.com/test/di/testkit"
+	"example.com/test/domain"
+)
+
+func TestContainer_Handler(t *testing.T) {
+	c, err := container.NewContainer(&domain.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler, err := c.Handler(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handler.Repository.Connection() == nil {
+		t.Fatal("connection is not created")
+	}
+}
+
+func TestContainer_Handler_Error(t *testing.T) {
+	config := &domain.Config{}
+	config.Unavailable.Store(true)
+	c, err := container.NewContainer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Handler(context.Background())
+	if !errors.Is(err, domain.ErrConnectionRefused) {
+		t.Fatalf("want connection error, got %v", err)
+	}
+}
+
+func TestNewTestContainer(t *testing.T) {
+	c := testkit.NewTestContainer(t, &domain.Config{})
+
+	if _, err := c.Handler(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,20 @@
+package providers
+
+import (
+	"context"
+
+	"example.com/test/di/contracts"
+	"example.com/test/domain"
+)
+
+func CreateConnection(ctx context.Context, c contracts.Container) (*domain.Connection, error) {
+	if c.Config(ctx).Unavailable.Load() {
+		return nil, domain.ErrConnectionRefused
+	}
+
+	return &domain.Connection{}, nil
+}
+
+func CreateHandler(ctx context.Context, c contracts.Container) (*domain.Handler, error) {
+	return &domain.Handler{Repository: c.Repositories().EntityRepository(ctx)}, nil
+}
@@ -0,0 +1,24 @@
+package providers_test
+
+import (
+	"context"
+	"testing"
+
+	"example.com/test/di/contracts/fakes"
+	"example.com/test/di/providers"
+	"example.com/test/domain"
+)
+
+func TestCreateRepositoriesEntityRepository(t *testing.T) {
+	connection := &domain.Connection{}
+	c := &fakes.Container{ConnectionFunc: fakes.Value(connection)}
+
+	repository, err := providers.CreateRepositoriesEntityRepository(context.Background(), c)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repository.Connection() != connection {
+		t.Fatal("unexpected connection")
+	}
+}
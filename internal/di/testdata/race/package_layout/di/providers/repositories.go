@@ -0,0 +1,20 @@
+package providers
+
+import (
+	"context"
+
+	"example.com/test/di/contracts"
+	"example.com/test/domain"
+)
+
+type entityRepository struct {
+	connection *domain.Connection
+}
+
+func (r *entityRepository) Connection() *domain.Connection {
+	return r.connection
+}
+
+func CreateRepositoriesEntityRepository(ctx context.Context, c contracts.Container) (domain.EntityRepository, error) {
+	return &entityRepository{connection: c.Connection(ctx)}, nil
+}
@@ -0,0 +1,56 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"example.com/test/di"
+	"example.com/test/di/internal"
+	"example.com/test/domain"
+)
+
+func TestContainer_PanicRecovery(t *testing.T) {
+	config := &domain.Config{}
+	config.Unavailable.Store(true)
+	c, err := di.NewContainer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Handler(context.Background())
+
+	want := "get Handler: create Handler -> Repositories.EntityRepository -> Connection: panic: connection refused"
+	if err == nil || err.Error() != want {
+		t.Fatalf("want error %q, got %v", want, err)
+	}
+	var serviceErr *di.ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.ID != "Connection" {
+		t.Fatalf("want service error of Connection, got %v", err)
+	}
+	var panicErr *di.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("want panic error, got %T", err)
+	}
+	if panicErr.Value != domain.ErrConnectionRefused {
+		t.Fatalf("unexpected panic value: %v", panicErr.Value)
+	}
+	if !strings.Contains(string(panicErr.Stack), "factories.CreateConnection") {
+		t.Fatalf("stack trace does not contain the factory:\n%s", panicErr.Stack)
+	}
+}
+
+func TestInternalContainer_PanicRecovery(t *testing.T) {
+	config := &domain.Config{}
+	config.Unavailable.Store(true)
+	c := internal.NewContainer()
+	c.SetConfig(config)
+
+	c.Handler(context.Background())
+
+	var panicErr *internal.PanicError
+	if !errors.As(c.Error(), &panicErr) {
+		t.Fatalf("want panic error, got %v", c.Error())
+	}
+}
@@ -0,0 +1,99 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"example.com/test/di"
+	"example.com/test/di/internal/factories"
+	"example.com/test/domain"
+)
+
+func TestContainer_ConcurrentGetters(t *testing.T) {
+	c, err := di.NewContainer(&domain.Config{Delay: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const goroutines = 50
+	handlers := make([]*domain.Handler, goroutines)
+	wg := sync.WaitGroup{}
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			handler, err := c.Handler(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			handlers[i] = handler
+		}(i)
+	}
+	wg.Wait()
+
+	for _, handler := range handlers {
+		if handler != handlers[0] {
+			t.Fatal("handler initialized more than once")
+		}
+	}
+	if created := factories.ConnectionsCreated.Load(); created != 1 {
+		t.Fatalf("connection created %d times", created)
+	}
+
+	connection := handlers[0].Repository.Connection()
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !connection.IsClosed() {
+		t.Fatal("connection is not closed")
+	}
+}
+
+func TestContainer_CloseAfterDeadline(t *testing.T) {
+	c, err := di.NewContainer(&domain.Config{CloseDelay: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, err := c.Handler(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	connection := handler.Repository.Connection()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = c.Close(ctx)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if connection.IsClosed() {
+		t.Fatal("connection is closed while handler is still closing")
+	}
+}
+
+func TestContainer_ConcurrentCloseAndGetter(t *testing.T) {
+	c, err := di.NewContainer(&domain.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Handler(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_ = c.Close(context.Background())
+		}()
+	}
+	wg.Wait()
+}
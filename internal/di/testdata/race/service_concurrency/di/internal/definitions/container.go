@@ -0,0 +1,17 @@
+package definitions
+
+import (
+	"example.com/test/domain"
+)
+
+type Container struct {
+	Connection *domain.Connection `di:"close"`
+	Handler    *domain.Handler    `di:"public,close=Close()"`
+
+	Repositories RepositoryContainer
+}
+
+type RepositoryContainer struct {
+	EntityRepository domain.EntityRepository `di:"set"`
+	Config           *domain.Config          `di:"required"`
+}
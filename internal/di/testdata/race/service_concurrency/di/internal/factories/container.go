@@ -0,0 +1,26 @@
+package factories
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"example.com/test/di/lookup"
+	"example.com/test/domain"
+)
+
+var ConnectionsCreated atomic.Int32
+
+func CreateConnection(ctx context.Context, c lookup.Container) (*domain.Connection, error) {
+	ConnectionsCreated.Add(1)
+	time.Sleep(c.Repositories().Config(ctx).Delay)
+
+	return &domain.Connection{}, nil
+}
+
+func CreateHandler(ctx context.Context, c lookup.Container) (*domain.Handler, error) {
+	return &domain.Handler{
+		Repository: c.Repositories().EntityRepository(ctx),
+		CloseDelay: c.Repositories().Config(ctx).CloseDelay,
+	}, nil
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+type Config struct {
+	Delay      time.Duration
+	CloseDelay time.Duration
+}
+
+type Connection struct {
+	closed atomic.Bool
+}
+
+func (c *Connection) Close() error {
+	c.closed.Store(true)
+
+	return nil
+}
+
+func (c *Connection) IsClosed() bool {
+	return c.closed.Load()
+}
+
+type EntityRepository interface {
+	Connection() *Connection
+}
+
+type Handler struct {
+	Repository EntityRepository
+	// CloseDelay blocks Close regardless of the context.
+	CloseDelay time.Duration
+}
+
+func (h *Handler) Close() {
+	time.Sleep(h.CloseDelay)
+}
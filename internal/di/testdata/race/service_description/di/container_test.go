@@ -0,0 +1,104 @@
+package di_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"example.com/test/di"
+	"example.com/test/domain"
+)
+
+func TestContainer_Describe(t *testing.T) {
+	config := &domain.Config{}
+	config.Unavailable.Store(true)
+	c, err := di.NewContainer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Handler(context.Background())
+	if !errors.Is(err, domain.ErrConnectionRefused) {
+		t.Fatalf("want connection error, got %v", err)
+	}
+	connection := findService(t, c.Describe(), "Connection")
+	if connection.Initialized || !errors.Is(connection.Err, domain.ErrConnectionRefused) {
+		t.Fatalf("want failed connection, got %+v", connection)
+	}
+
+	config.Unavailable.Store(false)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Handler(context.Background()); err != nil {
+				t.Error(err)
+			}
+			c.Describe()
+		}()
+	}
+	wg.Wait()
+
+	services := c.Describe()
+	wantOrder := map[string]int{"Connection": 1, "Repositories.EntityRepository": 2, "Handler": 3, "Clock": 0}
+	for id, order := range wantOrder {
+		service := findService(t, services, id)
+		if service.Order != order {
+			t.Errorf("want order %d of %s, got %d", order, id, service.Order)
+		}
+		if service.Initialized != (order > 0) || service.Err != nil {
+			t.Errorf("unexpected state of %s: %+v", id, service)
+		}
+	}
+	handler := findService(t, services, "Handler")
+	if handler.Duration <= 0 || !handler.Public || handler.Factory != "example.com/test/di/internal/factories.CreateHandler" {
+		t.Errorf("unexpected description of Handler: %+v", handler)
+	}
+	if clock := findService(t, services, "Clock"); clock.CloseMethod != "Close" || clock.Type != "*domain.Clock" {
+		t.Errorf("unexpected description of Clock: %+v", clock)
+	}
+}
+
+func TestContainer_DescribeHandler(t *testing.T) {
+	c, err := di.NewContainer(&domain.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Handler(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	c.DescribeHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/di", nil))
+
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+	var services []map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &services); err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 5 {
+		t.Fatalf("want 5 services, got %d", len(services))
+	}
+	repository := services[4]
+	if repository["id"] != "Repositories.EntityRepository" || repository["initialized"] != true {
+		t.Fatalf("unexpected description of repository: %v", repository)
+	}
+}
+
+func findService(t *testing.T, services []di.ServiceInfo, id string) di.ServiceInfo {
+	t.Helper()
+	for _, service := range services {
+		if service.ID == id {
+			return service
+		}
+	}
+	t.Fatalf("service %s not found", id)
+
+	return di.ServiceInfo{}
+}
@@ -0,0 +1,100 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"example.com/test/di"
+	"example.com/test/di/ditest"
+	"example.com/test/domain"
+)
+
+type fakeRepository struct {
+	connection *domain.Connection
+}
+
+func (r *fakeRepository) Connection() *domain.Connection {
+	return r.connection
+}
+
+func TestNewTestContainer_OverridePrivateService(t *testing.T) {
+	config := &domain.Config{}
+	// the real connection is never created by the fake repository
+	config.Unavailable.Store(true)
+	fake := &fakeRepository{connection: &domain.Connection{}}
+
+	c := ditest.NewTestContainer(t, config, ditest.OverrideRepositoriesEntityRepository(fake))
+
+	handler, err := c.Handler(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handler.Repository != fake {
+		t.Fatal("repository is not overridden")
+	}
+}
+
+func TestNewTestContainer_Clone(t *testing.T) {
+	prepared := ditest.NewTestContainer(t, &domain.Config{},
+		ditest.OverrideRepositoriesEntityRepository(&fakeRepository{}),
+	)
+	clocks := make(chan *domain.Clock, 2)
+
+	t.Run("group", func(t *testing.T) {
+		for _, name := range []string{"first", "second"} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				clock := &domain.Clock{}
+				c := prepared.Clone(t, ditest.OverrideClock(clock))
+
+				handler, err := c.Handler(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, ok := handler.Repository.(*fakeRepository); !ok {
+					t.Fatal("overrides are not cloned")
+				}
+				got, err := c.Clock(context.Background())
+				if err != nil || got != clock {
+					t.Fatalf("clock is not overridden: %v", err)
+				}
+				clocks <- clock
+			})
+		}
+	})
+	close(clocks)
+
+	for clock := range clocks {
+		// overridden services are fakes owned by the test, so they are not closed by the container
+		if clock.Closed.Load() {
+			t.Fatal("overridden service is closed by the container")
+		}
+	}
+}
+
+func TestNewTestContainer_Cleanup(t *testing.T) {
+	var c *ditest.TestContainer
+	t.Run("test", func(t *testing.T) {
+		c = ditest.NewTestContainer(t, &domain.Config{})
+		if _, err := c.Clock(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	clock, err := c.Clock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !clock.Closed.Load() {
+		t.Fatal("container is not closed by cleanup")
+	}
+}
+
+func TestOverride_UnexpectedType(t *testing.T) {
+	_, err := di.NewContainer(&domain.Config{}, di.Override("Clock", &domain.Handler{}))
+
+	want := "unexpected type *domain.Handler of service Clock"
+	if err == nil || err.Error() != want {
+		t.Fatalf("want error %q, got %v", want, err)
+	}
+}
@@ -0,0 +1,269 @@
+package di
+
+import (
+	"go/types"
+
+	"github.com/muonsoft/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// TypeLoader loads Go types used in service definitions. It is used by the generator
+// in type-checked mode to validate definitions at generation time.
+type TypeLoader interface {
+	LoadType(packagePath, name string) (types.Type, error)
+}
+
+// PackagesTypeLoader loads types from the packages of the current module.
+type PackagesTypeLoader struct {
+	dir      string
+	packages map[string]*types.Package
+}
+
+func NewPackagesTypeLoader(dir string) *PackagesTypeLoader {
+	return &PackagesTypeLoader{
+		dir:      dir,
+		packages: make(map[string]*types.Package),
+	}
+}
+
+func (l *PackagesTypeLoader) LoadType(packagePath, name string) (types.Type, error) {
+	pkg, err := l.loadPackage(packagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	object := pkg.Scope().Lookup(name)
+	if object == nil {
+		return nil, errors.Errorf("%w: type %s not found in package %s", ErrTypeCheck, name, packagePath)
+	}
+	typeName, ok := object.(*types.TypeName)
+	if !ok {
+		return nil, errors.Errorf("%w: %s.%s is not a type", ErrTypeCheck, packagePath, name)
+	}
+
+	return typeName.Type(), nil
+}
+
+func (l *PackagesTypeLoader) loadPackage(packagePath string) (*types.Package, error) {
+	if pkg, ok := l.packages[packagePath]; ok {
+		return pkg, nil
+	}
+
+	loaded, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedImports | packages.NeedDeps,
+		Dir:  l.dir,
+	}, packagePath)
+	if err != nil {
+		return nil, errors.Errorf("load package %s: %w", packagePath, err)
+	}
+	if len(loaded) != 1 || loaded[0].Types == nil {
+		return nil, errors.Errorf("%w: package %s not found", ErrTypeCheck, packagePath)
+	}
+	if len(loaded[0].Errors) > 0 {
+		return nil, errors.Errorf("load package %s: %w", packagePath, loaded[0].Errors[0])
+	}
+
+	l.packages[packagePath] = loaded[0].Types
+
+	return loaded[0].Types, nil
+}
+
+// TypeChecker validates service definitions against the actual Go types.
+type TypeChecker struct {
+	loader    TypeLoader
+	container *RootContainerDefinition
+}
+
+func NewTypeChecker(loader TypeLoader, container *RootContainerDefinition) *TypeChecker {
+	return &TypeChecker{loader: loader, container: container}
+}
+
+func (c *TypeChecker) Check() error {
+	for _, service := range c.container.Services {
+		if err := c.checkService(service); err != nil {
+			return err
+		}
+	}
+	for _, container := range c.container.Containers {
+		for _, service := range container.Services {
+			if err := c.checkService(service); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *TypeChecker) checkService(service *ServiceDefinition) error {
+	if service.Source != nil {
+		if err := c.checkSource(service); err != nil {
+			return errors.Errorf("service %s: from: %w", service.FullName(), err)
+		}
+	}
+
+	return nil
+}
+
+// checkSource checks that the field path exists on the source service and the field
+// is assignable to the service.
+func (c *TypeChecker) checkSource(service *ServiceDefinition) error {
+	fieldType, err := c.resolveType(service.Source.Type)
+	if err != nil {
+		return err
+	}
+	for _, name := range service.SourceFields {
+		object, _, _ := types.LookupFieldOrMethod(fieldType, true, nil, name)
+		field, ok := object.(*types.Var)
+		if !ok || !field.IsField() || !field.Exported() {
+			return errors.Errorf("%w: field %s not found on type %s", ErrTypeCheck, name, fieldType)
+		}
+		fieldType = field.Type()
+	}
+
+	serviceType, err := c.resolveType(service.Type)
+	if err != nil {
+		return err
+	}
+	if !types.AssignableTo(fieldType, serviceType) {
+		return errors.Errorf(
+			"%w: field %s of type %s cannot be assigned to %s",
+			ErrTypeCheck, service.From, fieldType, serviceType,
+		)
+	}
+
+	return nil
+}
+
+func (c *TypeChecker) resolveType(definition TypeDefinition) (types.Type, error) {
+	var resolved types.Type
+	if definition.Package == "" {
+		object := types.Universe.Lookup(definition.Name)
+		if object == nil {
+			return nil, errors.Errorf("%w: unknown type %s", ErrTypeCheck, definition.Name)
+		}
+		resolved = object.Type()
+	} else {
+		packagePath := c.container.PackageName(definition)
+		if packagePath == "" {
+			return nil, errors.Errorf("%w: unknown package %s", ErrTypeCheck, definition.Package)
+		}
+		t, err := c.loader.LoadType(packagePath, definition.Name)
+		if err != nil {
+			return nil, err
+		}
+		resolved = t
+	}
+
+	if definition.IsPointer {
+		resolved = types.NewPointer(resolved)
+	} else if definition.IsSlice {
+		resolved = types.NewSlice(resolved)
+	} else if definition.IsMap() {
+		key, err := c.resolveType(*definition.Key)
+		if err != nil {
+			return nil, err
+		}
+		resolved = types.NewMap(key, resolved)
+	}
+
+	return resolved, nil
+}
+
+// MethodResolver resolves signatures of close, start and stop methods of services in type-checked mode,
+// resolved signatures replace the signatures from the options of services.
+type MethodResolver struct {
+	checker *TypeChecker
+}
+
+func NewMethodResolver(loader TypeLoader, container *RootContainerDefinition) *MethodResolver {
+	return &MethodResolver{checker: NewTypeChecker(loader, container)}
+}
+
+func (r *MethodResolver) Resolve() error {
+	for _, service := range r.checker.container.Services {
+		if err := r.resolveService(service); err != nil {
+			return err
+		}
+	}
+	for _, container := range r.checker.container.Containers {
+		for _, service := range container.Services {
+			if err := r.resolveService(service); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *MethodResolver) resolveService(service *ServiceDefinition) error {
+	type methodOption struct{ option, name string }
+
+	methods := make([]methodOption, 0, 3)
+	if service.HasCloser {
+		methods = append(methods, methodOption{option: "close", name: service.CloseMethod})
+	}
+	if service.StartMethod != "" {
+		methods = append(methods, methodOption{option: "start", name: service.StartMethod})
+	}
+	if service.StopMethod != "" {
+		methods = append(methods, methodOption{option: "stop", name: service.StopMethod})
+	}
+	if len(methods) == 0 {
+		return nil
+	}
+
+	serviceType, err := r.checker.resolveType(service.Type)
+	if err != nil {
+		return errors.Errorf("service %s: %w", service.FullName(), err)
+	}
+	for _, method := range methods {
+		signature, err := resolveMethod(serviceType, method.name)
+		if err != nil {
+			return errors.Errorf("service %s: %s: %w", service.FullName(), method.option, err)
+		}
+		service.Methods[method.name] = signature
+	}
+
+	return nil
+}
+
+// resolveMethod checks that the method exists and has one of the signatures supported by
+// generated code: with or without context argument and with or without error result.
+func resolveMethod(t types.Type, name string) (MethodSignature, error) {
+	object, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	method, ok := object.(*types.Func)
+	if !ok {
+		return MethodSignature{}, errors.Errorf("%w: method %s not found on type %s", ErrTypeCheck, name, t)
+	}
+
+	signature := method.Type().(*types.Signature)
+	params := signature.Params()
+	results := signature.Results()
+	if params.Len() > 1 || params.Len() == 1 && !isContext(params.At(0).Type()) {
+		return MethodSignature{}, errors.Errorf(
+			"%w: method %s of type %s must have no arguments or context argument only", ErrTypeCheck, name, t,
+		)
+	}
+	if results.Len() > 1 || results.Len() == 1 && !isError(results.At(0).Type()) {
+		return MethodSignature{}, errors.Errorf(
+			"%w: method %s of type %s must return nothing or error only", ErrTypeCheck, name, t,
+		)
+	}
+
+	return MethodSignature{WithContext: params.Len() == 1, ReturnsError: results.Len() == 1}, nil
+}
+
+func isContext(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+
+	return named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
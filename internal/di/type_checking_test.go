@@ -0,0 +1,243 @@
+package di_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strider2038/digen/internal/di"
+)
+
+func TestGenerator_Generate_TypeCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		definitions string
+		wantErr     string
+	}{
+		{
+			name: "supported closer methods",
+			definitions: `package definitions
+import "example.com/test/server"
+type Container struct {
+	Server       *server.Server       ` + "`di:\"close=Shutdown\"`" + `
+	Worker       *server.Server       ` + "`di:\"close=Stop\"`" + `
+	Closer       *server.Server       ` + "`di:\"close\"`" + `
+	Connection   server.Connection    ` + "`di:\"close\"`" + `
+}`,
+		},
+		{
+			name: "supported lifecycle methods",
+			definitions: `package definitions
+import "example.com/test/server"
+type Container struct {
+	Server *server.Server ` + "`di:\"start=ListenAndServe,stop=Shutdown\"`" + `
+}`,
+		},
+		{
+			name: "start method not found",
+			definitions: `package definitions
+import "example.com/test/server"
+type Container struct {
+	Server *server.Server ` + "`di:\"start=Serve,stop=Shutdown\"`" + `
+}`,
+			wantErr: "resolve methods: service Server: start: type check failed: " +
+				"method Serve not found on type *example.com/test/server.Server",
+		},
+		{
+			name: "closer method not found",
+			definitions: `package definitions
+import "example.com/test/server"
+type Container struct {
+	Server *server.Server ` + "`di:\"close=GracefulStop\"`" + `
+}`,
+			wantErr: "resolve methods: service Server: close: type check failed: " +
+				"method GracefulStop not found on type *example.com/test/server.Server",
+		},
+		{
+			name: "closer method with unsupported arguments",
+			definitions: `package definitions
+import "example.com/test/server"
+type Container struct {
+	Server *server.Server ` + "`di:\"close=Kill\"`" + `
+}`,
+			wantErr: "resolve methods: service Server: close: type check failed: " +
+				"method Kill of type *example.com/test/server.Server must have no arguments or context argument only",
+		},
+		{
+			name: "closer method with unsupported results",
+			definitions: `package definitions
+import "example.com/test/server"
+type Container struct {
+	Server *server.Server ` + "`di:\"close=Done\"`" + `
+}`,
+			wantErr: "resolve methods: service Server: close: type check failed: " +
+				"method Done of type *example.com/test/server.Server must return nothing or error only",
+		},
+		{
+			name: "field bindings across containers",
+			definitions: `package definitions
+import (
+	"time"
+	"example.com/test/config"
+)
+type Container struct {
+	Config *config.Params ` + "`di:\"required\"`" + `
+	Port   int            ` + "`di:\"from=Config.HTTP.Port\"`" + `
+	Params ParamsContainer
+}
+type ParamsContainer struct {
+	Database config.Database ` + "`di:\"from=Config.Database\"`" + `
+	DSN      string          ` + "`di:\"from=Params.Database.DSN\"`" + `
+	Timeout  time.Duration   ` + "`di:\"from=Config.HTTP.Timeout\"`" + `
+}`,
+		},
+		{
+			name: "field binding not found",
+			definitions: `package definitions
+import "example.com/test/config"
+type Container struct {
+	Config *config.Params ` + "`di:\"required\"`" + `
+	Port   int            ` + "`di:\"from=Config.HTTP.Address\"`" + `
+}`,
+			wantErr: "check types: service Port: from: type check failed: " +
+				"field Address not found on type example.com/test/config.HTTP",
+		},
+		{
+			name: "field binding to unexported field",
+			definitions: `package definitions
+import "example.com/test/config"
+type Container struct {
+	Config *config.Params ` + "`di:\"required\"`" + `
+	Secret string         ` + "`di:\"from=Config.secret\"`" + `
+}`,
+			wantErr: "check types: service Secret: from: type check failed: " +
+				"field secret not found on type *example.com/test/config.Params",
+		},
+		{
+			name: "field binding of different type",
+			definitions: `package definitions
+import "example.com/test/config"
+type Container struct {
+	Config *config.Params ` + "`di:\"required\"`" + `
+	Port   string         ` + "`di:\"from=Config.HTTP.Port\"`" + `
+}`,
+			wantErr: "check types: service Port: from: type check failed: " +
+				"field Config.HTTP.Port of type int cannot be assigned to string",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			afs := afero.NewMemMapFs()
+			err := afero.WriteFile(afs, "./di/internal/definitions/container.go", []byte(test.definitions), 0644)
+			require.NoError(t, err)
+
+			generator := &di.Generator{
+				BaseDir:    "di",
+				ModulePath: "example.com/test",
+				FS:         afs,
+				Params:     di.GenerationParameters{TypeCheck: true},
+				TypeLoader: newSourceTypeLoader(t, map[string]string{
+					"example.com/test/server": testServerPackageSource,
+					"example.com/test/config": testConfigPackageSource,
+				}),
+			}
+			err = generator.Generate()
+
+			if test.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, di.ErrTypeCheck)
+				assert.EqualError(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+const testServerPackageSource = `package server
+
+import "context"
+
+type Server struct{}
+
+func (s *Server) ListenAndServe() error               { return nil }
+func (s *Server) Shutdown(ctx context.Context) error { return nil }
+func (s *Server) Stop()                               {}
+func (s *Server) Close() error                        { return nil }
+func (s *Server) Kill(force bool)                     {}
+func (s *Server) Done() <-chan struct{}               { return nil }
+
+type Connection interface {
+	Close()
+}
+`
+
+const testConfigPackageSource = `package config
+
+import "time"
+
+type Params struct {
+	HTTP     HTTP
+	Database Database
+	secret   string
+}
+
+type HTTP struct {
+	Port    int
+	Timeout time.Duration
+}
+
+type Database struct {
+	DSN string
+}
+`
+
+// sourceTypeLoader loads types from packages described by source code.
+type sourceTypeLoader struct {
+	importer types.Importer
+	packages map[string]*types.Package
+}
+
+func newSourceTypeLoader(t *testing.T, sources map[string]string) *sourceTypeLoader {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	loader := &sourceTypeLoader{
+		importer: config.Importer,
+		packages: make(map[string]*types.Package, len(sources)),
+	}
+
+	for path, source := range sources {
+		file, err := parser.ParseFile(fset, path+".go", source, 0)
+		require.NoError(t, err, "parse package %s", path)
+		pkg, err := config.Check(path, fset, []*ast.File{file}, nil)
+		require.NoError(t, err, "check package %s", path)
+		loader.packages[path] = pkg
+	}
+
+	return loader
+}
+
+func (l *sourceTypeLoader) LoadType(packagePath, name string) (types.Type, error) {
+	pkg, ok := l.packages[packagePath]
+	if !ok {
+		// standard library packages
+		var err error
+		pkg, err = l.importer.Import(packagePath)
+		if err != nil {
+			return nil, di.ErrTypeCheck
+		}
+	}
+	object := pkg.Scope().Lookup(name)
+	if object == nil {
+		return nil, di.ErrTypeCheck
+	}
+
+	return object.Type(), nil
+}
@@ -1,7 +1,6 @@
 package di
 
 import (
-	"os"
 	"path/filepath"
 
 	"github.com/muonsoft/errors"
@@ -11,7 +10,6 @@ import (
 type Writer struct {
 	FS        afero.Fs
 	Overwrite bool
-	Append    bool
 }
 
 func NewWriter(fs afero.Fs) *Writer {
@@ -20,9 +18,6 @@ func NewWriter(fs afero.Fs) *Writer {
 
 func (w *Writer) WriteFile(file *File) error {
 	if isFileExist(w.FS, file.Name) {
-		if w.Append {
-			return w.append(file, file.Name)
-		}
 		if !w.Overwrite {
 			return errors.Errorf("cannot write to file %s: %w", file.Name, ErrFileAlreadyExists)
 		}
@@ -46,24 +41,8 @@ func (w *Writer) write(file *File, filename string) error {
 	return nil
 }
 
-func (w *Writer) append(file *File, filename string) error {
-	return appendFile(w.FS, filename, file.Content, 0644)
-}
-
 func isFileExist(fs afero.Fs, filename string) bool {
 	_, err := fs.Stat(filename)
 
 	return err == nil
 }
-
-func appendFile(fs afero.Fs, name string, data []byte, perm os.FileMode) error {
-	f, err := fs.OpenFile(name, os.O_APPEND|os.O_WRONLY|os.O_CREATE, perm)
-	if err != nil {
-		return err
-	}
-	_, err = f.Write(data)
-	if err1 := f.Close(); err1 != nil && err == nil {
-		err = err1
-	}
-	return err
-}